@@ -21,19 +21,17 @@ import (
 	"flag"
 	"os"
 	"time"
+	// Embed the time zone database so that schedule.hpa.infraflow.co/timeZone works in images without tzdata.
+	_ "time/tzdata"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
-	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
-
-	// autoscalingv1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -41,6 +39,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	"github.com/infraflows/autoscale-controller/internal/controller"
+	webhookv1 "github.com/infraflows/autoscale-controller/internal/webhook/v1"
+	autoscalev1alpha1 "github.com/infraflows/autoscale-controller/pkg/apis/autoscale/v1alpha1"
+	vpav1 "github.com/infraflows/autoscale-controller/pkg/apis/vpa/v1"
+	"github.com/infraflows/autoscale-controller/pkg/kube"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -51,7 +53,8 @@ var (
 
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
-	// utilruntime.Must(autoscalingv1.AddToScheme(scheme))
+	utilruntime.Must(vpav1.AddToScheme(scheme))
+	utilruntime.Must(autoscalev1alpha1.AddToScheme(scheme))
 
 	// +kubebuilder:scaffold:scheme
 }
@@ -62,6 +65,13 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var enableVPA bool
+	var resyncPeriod time.Duration
+	var defaultsProfilesFile string
+	var autoscalerEvents bool
+	var forceOwnership bool
+	var globalPause bool
+	var hpaNameTemplate string
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -73,6 +83,28 @@ func main() {
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.BoolVar(&enableVPA, "enable-vpa", false,
+		"If set, VerticalPodAutoscalers are managed from vpa.infraflow.co/ annotations. "+
+			"VPA management is skipped when the VPA CRD is not installed in the cluster.")
+	flag.DurationVar(&resyncPeriod, "resync-period", 0,
+		"If set, managed workloads are periodically reconciled at this interval in addition to watch events. "+
+			"0 disables the periodic resync.")
+	flag.StringVar(&defaultsProfilesFile, "defaults-profiles-file", "",
+		"The file of the defaults profiles selected by the autoscale.infraflow.co/defaults namespace label. "+
+			"If empty, no defaults are applied to Deployments.")
+	flag.StringVar(&hpaNameTemplate, "hpa-name-template", "",
+		"The Go template of the HPA names, e.g. '{{.Name}}-{{.Kind | lower}}-hpa', with the fields .Name, .Namespace "+
+			"and .Kind of the workload. Workloads may override it with the hpa.infraflow.co/nameTemplate annotation. "+
+			"If empty, HPAs are named like the VPAs.")
+	flag.BoolVar(&globalPause, "global-pause", false,
+		"If set, the HPAs of all workloads are paused as with the hpa.infraflow.co/paused annotation: their minReplicas "+
+			"and maxReplicas are pinned to the current replica count until the controller is restarted without it.")
+	flag.BoolVar(&forceOwnership, "force-ownership", false,
+		"If set, HPAs and VPAs are applied with forced ownership, taking over fields managed by other field managers. "+
+			"Otherwise conflicting fields are left alone and reported as HPAConflict or VPAConflict events.")
+	flag.BoolVar(&autoscalerEvents, "autoscaler-events", false,
+		"If set, lifecycle events of the managed HPAs and VPAs are also recorded on the HPA and VPA objects, "+
+			"not only on the workloads.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -137,15 +169,6 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "7f597a5b.infraflow.co",
-		Client: client.Options{
-			Cache: &client.CacheOptions{
-				DisableFor: []client.Object{
-					&appsv1.Deployment{},
-					&appsv1.StatefulSet{},
-					&appsv1.DaemonSet{},
-				},
-			},
-		},
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -163,14 +186,43 @@ func main() {
 		os.Exit(1)
 	}
 
+	nameTemplate, err := kube.ParseNameTemplate(hpaNameTemplate)
+	if err != nil {
+		setupLog.Error(err, "invalid HPA name template", "template", hpaNameTemplate)
+		os.Exit(1)
+	}
+
 	if err = (&controller.AutoScaleReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 		Event:  mgr.GetEventRecorderFor("AutoScale"),
+
+		EnableVPA:    enableVPA,
+		ResyncPeriod: resyncPeriod,
+
+		HPANameTemplate:  nameTemplate,
+		GlobalPause:      globalPause,
+		ForceOwnership:   forceOwnership,
+		AutoscalerEvents: autoscalerEvents,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "AutoScale")
 		os.Exit(1)
 	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = webhookv1.SetupWorkloadWebhooksWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Workload")
+			os.Exit(1)
+		}
+		if err = webhookv1.SetupDeploymentDefaultsWebhookWithManager(mgr, defaultsProfilesFile); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DeploymentDefaults")
+			os.Exit(1)
+		}
+		if err = webhookv1.SetupPolicyWebhooksWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Policy")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
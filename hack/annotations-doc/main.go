@@ -0,0 +1,123 @@
+/*
+Copyright 2025 infraflows team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// annotations-doc renders the annotation tables of docs/annotations.md from the registry in
+// pkg/consts. Each table lives between a pair of markers:
+//
+//	<!-- BEGIN GENERATED: <section> -->
+//	<!-- END GENERATED: <section> -->
+//
+// where <section> is one of the consts.DocSection* values, or "deprecated" for the alias table.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+)
+
+const deprecatedSection = "deprecated"
+
+var markerRe = regexp.MustCompile(`(?s)(<!-- BEGIN GENERATED: ([a-z]+) -->\n).*?(<!-- END GENERATED: ([a-z]+) -->)`)
+
+func main() {
+	doc := flag.String("doc", "docs/annotations.md", "Path of the annotations document.")
+	check := flag.Bool("check", false, "Only check that the document is up to date.")
+	flag.Parse()
+
+	current, err := os.ReadFile(*doc)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	rendered, err := render(current)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *check {
+		if !bytes.Equal(current, rendered) {
+			fmt.Fprintf(os.Stderr, "%s is out of date, run 'make docs'\n", *doc)
+			os.Exit(1)
+		}
+		return
+	}
+	if err := os.WriteFile(*doc, rendered, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// render replaces the content between every pair of markers with the generated table.
+func render(doc []byte) ([]byte, error) {
+	var renderErr error
+	out := markerRe.ReplaceAllFunc(doc, func(match []byte) []byte {
+		groups := markerRe.FindSubmatch(match)
+		section, end := string(groups[2]), string(groups[4])
+		if section != end {
+			renderErr = fmt.Errorf("marker of section %q is closed by %q", section, end)
+			return match
+		}
+		table, err := table(section)
+		if err != nil {
+			renderErr = err
+			return match
+		}
+		return []byte(string(groups[1]) + table + string(groups[3]))
+	})
+	return out, renderErr
+}
+
+// table renders the markdown table of a section.
+func table(section string) (string, error) {
+	var b strings.Builder
+	if section == deprecatedSection {
+		b.WriteString("| 已废弃的 Annotation Key | 替代的 Annotation Key |\n")
+		b.WriteString("|------------------------|----------------------|\n")
+		aliases := make([]string, 0, len(consts.Aliases))
+		for alias := range consts.Aliases {
+			aliases = append(aliases, alias)
+		}
+		sort.Strings(aliases)
+		for _, alias := range aliases {
+			fmt.Fprintf(&b, "| `%s` | `%s` |\n", alias, consts.Aliases[alias])
+		}
+		return b.String(), nil
+	}
+
+	b.WriteString("| Annotation Key | 类型 | 示例值 | 描述 |\n")
+	b.WriteString("|----------------|------|--------|------|\n")
+	found := false
+	for _, d := range consts.AnnotationDocs {
+		if d.Section != section {
+			continue
+		}
+		found = true
+		fmt.Fprintf(&b, "| `%s` | string | %s | %s |\n", d.Key, d.Example, d.Description)
+	}
+	if !found {
+		return "", fmt.Errorf("unknown section %q", section)
+	}
+	return b.String(), nil
+}
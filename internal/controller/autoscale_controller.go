@@ -18,27 +18,38 @@ package controller
 
 import (
 	"context"
+	stderrors "errors"
+	"fmt"
+	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
+	autoscalev1alpha1 "github.com/infraflows/autoscale-controller/pkg/apis/autoscale/v1alpha1"
+	vpav1 "github.com/infraflows/autoscale-controller/pkg/apis/vpa/v1"
 	"github.com/infraflows/autoscale-controller/pkg/consts"
 	"github.com/infraflows/autoscale-controller/pkg/kube"
 	"github.com/infraflows/autoscale-controller/pkg/metrics"
-	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-
-	// v1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
-
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/csaupgrade"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -46,17 +57,51 @@ type AutoScaleReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 	Event  record.EventRecorder
+	// EnableVPA 是否管理VPA，集群中未安装VPA CRD时会在启动阶段自动关闭
+	EnableVPA bool
+	// ResyncPeriod 被管理的工作负载的重新同步周期，为0时只由事件触发协调
+	ResyncPeriod time.Duration
+	// HPANameTemplate HPA名称模板，工作负载可以通过 hpa.infraflow.co/nameTemplate 注解覆盖，见 kube.HPAName
+	HPANameTemplate *template.Template
+	// GlobalPause 暂停所有工作负载的HPA，见 kube.PauseHPA
+	GlobalPause bool
+	// ForceOwnership HPA/VPA的字段与其他 field manager 冲突时，是否强制获取字段的所有权
+	ForceOwnership bool
+	// AutoscalerEvents 是否同时在HPA/VPA上记录创建、更新、删除等事件，默认只记录在工作负载上
+	AutoscalerEvents bool
+	// Clock 计算定时窗口和状态时间使用的时钟，为nil时使用系统时间
+	Clock clock.PassiveClock
 }
 
 func init() {
 	metrics.Init()
 }
 
-func (r *AutoScaleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets;daemonsets,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=autoscaling.k8s.io,resources=verticalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=autoscale.infraflow.co,resources=autoscalepolicies;clusterautoscaleprofiles;clusterautoscalecalendars,verbs=get;list;watch
+
+// profileIndexField 按引用的 ClusterAutoscaleProfile 名称索引工作负载的字段
+const profileIndexField = "metadata.annotations[" + consts.HPAProfile + "]"
+
+// calendarIndexField 按 hpa.infraflow.co/calendar 引用的 ClusterAutoscaleCalendar 名称索引工作负载的字段
+const calendarIndexField = "metadata.annotations[" + consts.HPACalendar + "]"
+
+// forKind 返回指定类型工作负载的Reconciler，每种工作负载类型使用独立的controller，
+// 请求中的NamespacedName只对应该类型的工作负载，不同类型的同名工作负载互不影响
+func (r *AutoScaleReconciler) forKind(kind string) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+		return r.reconcile(ctx, req, kind)
+	})
+}
+
+func (r *AutoScaleReconciler) reconcile(ctx context.Context, req ctrl.Request, kind string) (result ctrl.Result, err error) {
 	logger := log.FromContext(ctx)
-	//logger.V(1).Info("Reconciling workload", "namespace", req.Namespace, "name", req.Name)
 
-	workload, kind, err := r.getWorkload(ctx, req)
+	workload, err := r.getWorkload(ctx, req, kind)
 	if err != nil {
 		logger.Error(err, "Failed to get workload")
 		return ctrl.Result{}, err
@@ -64,150 +109,656 @@ func (r *AutoScaleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 	if workload == nil {
 		logger.V(1).Info("There are no matching workloads.")
-		return ctrl.Result{RequeueAfter: 3 * time.Second}, nil
+		metrics.SetCalendarEntry(kind, req.Namespace, req.Name, "", "")
+		return ctrl.Result{}, nil
 	}
 
 	// 处理 finalizer
 	cleanupFn := func(ctx context.Context, obj client.Object) error {
-		return r.deleteHPA(ctx, obj)
+		if err := r.deleteHPA(ctx, obj, kind, ""); err != nil {
+			return err
+		}
+		if r.EnableVPA {
+			return r.deleteVPA(ctx, obj, kind)
+		}
+		return nil
 	}
 	if err := kube.HandleFinalizerWithCleanup(ctx, r.Client, workload, consts.AutoScaleFinalizer, logger, cleanupFn); err != nil {
 		logger.Error(err, "Failed to handle finalizer")
 		return ctrl.Result{}, err
 	}
+	if workload.GetDeletionTimestamp() != nil {
+		metrics.SetCalendarEntry(kind, req.Namespace, req.Name, "", "")
+		return ctrl.Result{}, nil
+	}
 
-	annotations := workload.GetAnnotations()
-	if r.shouldManageHPA(annotations) {
-		logger.Info("Found HPA annotations, starting async reconciliation",
-			"namespace", req.Namespace,
-			"name", req.Name,
-			"kind", kind)
-
-		// 异步处理 HPA 创建/更新
-		go func() {
-			asyncCtx := context.Background()
-			if err := r.reconcileHPA(asyncCtx, workload, kind); err != nil {
-				logger.Error(err, "Failed to reconcile HPA in async process")
+	annotations, deprecations := consts.NormalizeAnnotations(workload.GetAnnotations())
+	annotationsHash := kube.AnnotationsHash(workload.GetAnnotations())
+	// 废弃注解的警告只在自动扩缩容注解变化后记录一次，重新同步或策略、profile、日历变化触发的协调不再重复记录
+	if annotationsHash != observedAnnotationsHash(workload) {
+		for _, d := range deprecations {
+			r.Event.Eventf(workload, corev1.EventTypeWarning, "DeprecatedAnnotation",
+				"Annotation %s is deprecated, use %s instead", d.Key, d.Canonical)
+		}
+	}
+
+	policy, err := r.findPolicy(ctx, workload, kind)
+	if err != nil {
+		logger.Error(err, "Failed to list AutoscalePolicies")
+		return ctrl.Result{}, err
+	}
+
+	// HPA / VPA 的配置来自工作负载的注解或选中该工作负载的 AutoscalePolicy，见 hpaSpec、vpaSpec
+	capabilities := kube.CapabilitiesOf(kind)
+	manageHPA := capabilities.HPA && (r.shouldManageHPA(annotations) || policy != nil && policy.Spec.HPA != nil)
+	manageVPA := r.EnableVPA && capabilities.VPA && (r.shouldManageVPA(annotations) || policy != nil && policy.Spec.VPA != nil)
+	if !capabilities.HPA && r.shouldManageHPA(annotations) {
+		r.Event.Eventf(workload, corev1.EventTypeWarning, "HPAUnsupported",
+			"HPA annotations are ignored: %s has no scale subresource and cannot be scaled by a HorizontalPodAutoscaler", kind)
+	}
+
+	// 协调结束时（包括返回错误时）报告注解校验结果和自动扩缩容状态，见 reportStatus
+	status := &kube.AutoscaleStatus{ObservedAnnotationsHash: annotationsHash}
+	if policy != nil && (manageHPA && !r.shouldManageHPA(annotations) && policy.Spec.HPA != nil ||
+		manageVPA && !r.shouldManageVPA(annotations) && policy.Spec.VPA != nil) {
+		status.Policy = policy.Name
+	}
+	if manageHPA && r.shouldManageHPA(annotations) {
+		status.Profile = kube.ProfileName(workload)
+	}
+	// 注解校验错误不会因重试而恢复，汇总后通过事件和状态注解报告，不再重新入队
+	var invalid field.ErrorList
+	defer func() {
+		metrics.SetCalendarEntry(kind, workload.GetNamespace(), workload.GetName(), status.Calendar, status.CalendarEntry)
+		if statusErr := r.reportStatus(ctx, workload, manageHPA || manageVPA, status, invalid, err); statusErr != nil {
+			logger.Error(statusErr, "Failed to report autoscale status")
+			if err == nil {
+				err = statusErr
 			}
-		}()
+		}
+	}()
+
+	if manageHPA {
+		var conflict *conflictError
+		if err := r.reconcileHPA(ctx, workload, kind, policy, status); stderrors.As(err, &conflict) {
+			status.LastError = conflict.Error()
+		} else if err != nil && !validationErrors(err, &invalid) {
+			logger.Error(err, "Failed to reconcile HPA")
+			return ctrl.Result{}, fmt.Errorf("failed to reconcile HPA: %w", err)
+		}
 	} else {
-		// 如果没有 HPA 注解，静默删除可能存在的 HPA
-		if err := r.deleteHPA(ctx, workload); err != nil {
-			logger.V(1).Info("Failed to delete HPA", "error", err)
+		if err := r.deleteHPA(ctx, workload, kind, ""); err != nil {
+			logger.Error(err, "Failed to delete HPA")
+			return ctrl.Result{}, fmt.Errorf("failed to delete HPA: %w", err)
 		}
 	}
 
-	// if r.shouldManageVPA(annotations) {
-	// 	if err := r.reconcileVPA(ctx, workload, kind); err != nil {
-	// 		logger.Error(err, "Failed to reconcile VPA")
-	// 		return ctrl.Result{}, err
-	// 	}
-	// } else {
-	// 	if err := r.deleteVPA(ctx, workload); err != nil {
-	// 		logger.Error(err, "Failed to delete VPA")
-	// 		return ctrl.Result{}, err
-	// 	}
-	// }
+	if r.EnableVPA {
+		if manageVPA {
+			var conflict *conflictError
+			if err := r.reconcileVPA(ctx, workload, kind, policy); err == nil {
+				status.VPA = kube.AutoscalerName(workload.GetName(), kind)
+			} else if stderrors.As(err, &conflict) {
+				status.LastError = conflict.Error()
+			} else if !validationErrors(err, &invalid) {
+				logger.Error(err, "Failed to reconcile VPA")
+				return ctrl.Result{}, fmt.Errorf("failed to reconcile VPA: %w", err)
+			}
+		} else {
+			if err := r.deleteVPA(ctx, workload, kind); err != nil {
+				logger.Error(err, "Failed to delete VPA")
+				return ctrl.Result{}, fmt.Errorf("failed to delete VPA: %w", err)
+			}
+		}
+	}
 
-	return ctrl.Result{RequeueAfter: 3 * time.Second}, nil
+	// 配置了重新同步周期时，定期重新协调被管理的工作负载，用于修复遗漏的事件；
+	// 配置了定时窗口时，在下一次窗口开始或结束时重新协调，两者取较早的时间
+	if r.ResyncPeriod > 0 && (manageHPA || manageVPA) {
+		result.RequeueAfter = r.ResyncPeriod
+	}
+	if next := status.NextScheduleTime; next != nil {
+		if d := next.Sub(r.now()); result.RequeueAfter == 0 || d < result.RequeueAfter {
+			// 时钟已经越过边界时尽快重新协调，RequeueAfter 为0表示不重新入队
+			result.RequeueAfter = max(d, time.Millisecond)
+		}
+	}
+	return result, nil
 }
 
-// getWorkload 获取工作负载
-func (r *AutoScaleReconciler) getWorkload(ctx context.Context, req ctrl.Request) (client.Object, string, error) {
-	candidates := []client.Object{
-		&appsv1.Deployment{},
-		&appsv1.StatefulSet{},
-		&appsv1.DaemonSet{},
+// now 返回当前时间，测试中可以通过 Clock 注入
+func (r *AutoScaleReconciler) now() time.Time {
+	if r.Clock == nil {
+		return time.Now()
 	}
+	return r.Clock.Now()
+}
 
-	for _, c := range candidates {
-		err := r.Get(ctx, req.NamespacedName, c)
-		if err == nil {
-			return c, c.GetObjectKind().GroupVersionKind().Kind, nil
+// getWorkload 获取指定类型的工作负载，不存在时返回nil
+func (r *AutoScaleReconciler) getWorkload(ctx context.Context, req ctrl.Request, kind string) (client.Object, error) {
+	workload := kube.NewWorkload(kind)
+	if workload == nil {
+		return nil, fmt.Errorf("unsupported workload kind %q", kind)
+	}
+	if err := r.Get(ctx, req.NamespacedName, workload); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	return workload, nil
+}
+
+// findPolicy 返回选中工作负载的 AutoscalePolicy，没有时返回nil，选择规则见 kube.SelectPolicy
+func (r *AutoScaleReconciler) findPolicy(ctx context.Context, workload client.Object, kind string) (*autoscalev1alpha1.AutoscalePolicy, error) {
+	policies := &autoscalev1alpha1.AutoscalePolicyList{}
+	if err := r.List(ctx, policies, client.InNamespace(workload.GetNamespace())); err != nil {
+		return nil, err
+	}
+	return kube.SelectPolicy(policies.Items, workload, kind), nil
+}
+
+// hpaSpec 返回工作负载的HPA配置
+// 工作负载上存在HPA注解时只使用注解，忽略 AutoscalePolicy 中的HPA配置
+func (r *AutoScaleReconciler) hpaSpec(ctx context.Context, workload client.Object, policy *autoscalev1alpha1.AutoscalePolicy) (*autoscalev1alpha1.HPASpec, error) {
+	if r.shouldManageHPA(workload.GetAnnotations()) {
+		if policy != nil && policy.Spec.HPA != nil {
+			log.FromContext(ctx).V(1).Info("HPA annotations take precedence over AutoscalePolicy", "policy", policy.Name)
 		}
-		if !errors.IsNotFound(err) {
-			return nil, "", err
+		profile, err := r.getProfile(ctx, workload)
+		if err != nil {
+			return nil, err
+		}
+		return kube.HPASpecFromAnnotations(workload, profile)
+	}
+	if policy == nil {
+		return nil, nil
+	}
+	return policy.Spec.HPA, nil
+}
+
+// getProfile 返回工作负载通过 hpa.infraflow.co/profile 引用的 ClusterAutoscaleProfile 中的HPA配置，没有引用时返回nil
+// 引用的配置不存在时返回注解校验错误
+func (r *AutoScaleReconciler) getProfile(ctx context.Context, workload client.Object) (*autoscalev1alpha1.HPASpec, error) {
+	name := kube.ProfileName(workload)
+	if name == "" {
+		return nil, nil
+	}
+	profile := &autoscalev1alpha1.ClusterAutoscaleProfile{}
+	if err := r.Get(ctx, client.ObjectKey{Name: name}, profile); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, kube.ProfileNotFoundError(name)
+		}
+		return nil, err
+	}
+	return &profile.Spec.HPA, nil
+}
+
+// activeCalendarEntry 返回名为name的 ClusterAutoscaleCalendar 在now时生效的条目，以及下一次有条目开始或结束的时间，
+// 见 kube.ActiveCalendarEntry；name为空时返回nil，引用的日历不存在时返回注解校验错误
+func (r *AutoScaleReconciler) activeCalendarEntry(ctx context.Context, workload client.Object, name string,
+	now time.Time) (*autoscalev1alpha1.CalendarEntry, time.Time, error) {
+	if name == "" {
+		return nil, time.Time{}, nil
+	}
+	calendar := &autoscalev1alpha1.ClusterAutoscaleCalendar{}
+	if err := r.Get(ctx, client.ObjectKey{Name: name}, calendar); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, time.Time{}, kube.CalendarNotFoundError(workload, name)
 		}
+		return nil, time.Time{}, err
 	}
+	return kube.ActiveCalendarEntry(calendar, now)
+}
 
-	return nil, "", nil
+// vpaSpec 返回工作负载的VPA配置
+// 工作负载上存在VPA注解时只使用注解，忽略 AutoscalePolicy 中的VPA配置
+func (r *AutoScaleReconciler) vpaSpec(ctx context.Context, workload client.Object, policy *autoscalev1alpha1.AutoscalePolicy) (*autoscalev1alpha1.VPASpec, error) {
+	if r.shouldManageVPA(workload.GetAnnotations()) {
+		if policy != nil && policy.Spec.VPA != nil {
+			log.FromContext(ctx).V(1).Info("VPA annotations take precedence over AutoscalePolicy", "policy", policy.Name)
+		}
+		return kube.VPASpecFromAnnotations(workload)
+	}
+	if policy == nil {
+		return nil, nil
+	}
+	return policy.Spec.VPA, nil
 }
 
 // reconcileHPA 协调Horizontal Pod Autoscale
-// 1. 构建期望的HPA配置
-// 2. 检查现有HPA是否存在
-// 3. 创建新的HPA或更新现有的HPA
-func (r *AutoScaleReconciler) reconcileHPA(ctx context.Context, workload client.Object, kind string) error {
-	desired := kube.BuildDesiredHPA(workload, kind)
+// 1. 构建期望的HPA配置，定时窗口、日历条目生效时覆盖其中的副本数（见 kube.ActiveSchedule、kube.ActiveCalendarEntry）
+// 2. 创建或更新HPA，见 applyHPA
+// 3. HPA名称变化（见 kube.HPAName）时删除之前名称的HPA
+// 成功时将HPA名称、暂停原因和定时窗口写入status
+// 任一步骤失败时返回错误，由controller-runtime按指数退避重新入队
+func (r *AutoScaleReconciler) reconcileHPA(ctx context.Context, workload client.Object, kind string,
+	policy *autoscalev1alpha1.AutoscalePolicy, status *kube.AutoscaleStatus) error {
+	spec, err := r.hpaSpec(ctx, workload, policy)
+	if err != nil {
+		return err
+	}
+	paused, err := r.pauseReason(ctx, workload)
+	if err != nil {
+		return err
+	}
+	now := r.now()
+	window, next, err := kube.ActiveSchedule(spec.Schedules, now)
+	if err != nil {
+		return err
+	}
+	calendar := kube.CalendarName(workload, spec)
+	entry, calendarNext, err := r.activeCalendarEntry(ctx, workload, calendar, now)
+	if err != nil {
+		return err
+	}
+	// 日历条目在定时窗口之后应用，条目中的副本数作为下限，见 kube.ApplyCalendarEntry
+	spec = kube.ApplyCalendarEntry(kube.ApplySchedule(spec, window), entry)
+	if !calendarNext.IsZero() && (next.IsZero() || calendarNext.Before(next)) {
+		next = calendarNext
+	}
+	name, err := kube.HPAName(workload, kind, r.HPANameTemplate)
+	if err != nil {
+		return err
+	}
+	desired, err := kube.BuildDesiredHPA(workload, kind, name, spec)
+	if err != nil {
+		return err
+	}
+	if err := controllerutil.SetControllerReference(workload, desired, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.applyHPA(ctx, workload, desired, paused); err != nil {
+		return err
+	}
+	if err := r.deleteHPA(ctx, workload, kind, name); err != nil {
+		return err
+	}
+
+	status.HPA, status.Paused, status.Calendar = name, paused, calendar
+	if window != nil {
+		status.Schedule = kube.ScheduleName(window)
+	}
+	if entry != nil {
+		status.CalendarEntry = entry.Name
+	}
+	if !next.IsZero() {
+		status.NextScheduleTime = &metav1.Time{Time: next}
+	}
+	return nil
+}
+
+// applyHPA 检查现有HPA是否存在，通过 server-side apply 创建或更新HPA，见 apply
+// paused 不为空时HPA被暂停，最小、最大副本数固定为当前副本数（见 kube.PauseHPA），恢复时按配置重新写入
+// 已存在的同名HPA不是由Controller管理时（见 kube.IsManaged），只有工作负载设置了
+// hpa.infraflow.co/adopt: "true" 才会接管（强制获取字段的所有权），否则保持不变，记录冲突事件并返回 conflictError
+func (r *AutoScaleReconciler) applyHPA(ctx context.Context, workload client.Object, desired *autoscalingv2.HorizontalPodAutoscaler, paused string) error {
 	current := &autoscalingv2.HorizontalPodAutoscaler{}
 	err := r.Get(ctx, client.ObjectKeyFromObject(desired), current)
 	if errors.IsNotFound(err) {
-		controllerutil.SetControllerReference(workload, desired, r.Scheme)
-		return r.Create(ctx, desired)
+		if paused != "" {
+			kube.PauseHPA(desired, kube.PausedReplicas(nil, workload))
+		}
+		if err := r.apply(ctx, workload, desired, false); err != nil {
+			return err
+		}
+		r.recordEvent(workload, desired, corev1.EventTypeNormal, "HPACreated", "Created HorizontalPodAutoscaler %s", desired.Name)
+		if paused != "" {
+			r.recordPaused(workload, desired, paused)
+		}
+		return nil
 	} else if err != nil {
 		return err
 	}
-	controllerutil.SetControllerReference(workload, current, r.Scheme)
-	if !kube.EqualHPA(current, desired) {
-		current.Spec = desired.Spec
-		return r.Update(ctx, current)
+
+	if err := r.controllerConflict(workload, current, "HorizontalPodAutoscaler", "HPAConflict"); err != nil {
+		return err
+	}
+	adopted := false
+	if !kube.IsManaged(current, workload) {
+		if adopt, _ := strconv.ParseBool(workload.GetAnnotations()[consts.HPAAdopt]); !adopt {
+			conflict := &conflictError{message: fmt.Sprintf(
+				"HorizontalPodAutoscaler %s already exists and is not managed by the controller, set %s: \"true\" to adopt it",
+				current.Name, consts.HPAAdopt)}
+			r.recordEvent(workload, current, corev1.EventTypeWarning, "HPAConflict", "%s", conflict.Error())
+			return conflict
+		}
+		adopted = true
+	}
+	wasPaused := kube.IsPaused(current)
+	if paused != "" {
+		kube.PauseHPA(desired, kube.PausedReplicas(current, workload))
+	}
+	// 恢复时即使配置的副本数与暂停时固定的副本数相同，也需要移除 paused-replicas 注解
+	if !adopted && metav1.IsControlledBy(current, workload) && wasPaused == (paused != "") &&
+		kube.HasMetadata(current, desired) && kube.EqualHPA(current, desired) {
+		return nil
+	}
+
+	if err := r.upgradeManagedFields(ctx, current); err != nil {
+		return err
+	}
+	applied := desired.DeepCopy()
+	if err := r.apply(ctx, workload, applied, adopted); err != nil {
+		return err
+	}
+	if adopted {
+		r.recordEvent(workload, applied, corev1.EventTypeNormal, "HPAAdopted", "Adopted HorizontalPodAutoscaler %s", applied.Name)
+	}
+	switch {
+	case paused != "" && !wasPaused:
+		r.recordPaused(workload, applied, paused)
+	case paused == "" && wasPaused:
+		if diff := kube.DiffHPA(current, applied); len(diff) > 0 {
+			r.recordEvent(workload, applied, corev1.EventTypeNormal, "HPAResumed", "Resumed HorizontalPodAutoscaler %s: %s",
+				applied.Name, strings.Join(diff, ", "))
+		} else {
+			r.recordEvent(workload, applied, corev1.EventTypeNormal, "HPAResumed", "Resumed HorizontalPodAutoscaler %s", applied.Name)
+		}
+	case applied.ResourceVersion != current.ResourceVersion:
+		if diff := append(kube.DiffMetadata(current, desired), kube.DiffHPA(current, applied)...); len(diff) > 0 {
+			r.recordEvent(workload, applied, corev1.EventTypeNormal, "HPAUpdated",
+				"Updated HorizontalPodAutoscaler %s: %s", applied.Name, strings.Join(diff, ", "))
+		}
 	}
 	return nil
 }
 
-// deleteHPA 删除与工作负载关联的HPA
-func (r *AutoScaleReconciler) deleteHPA(ctx context.Context, workload client.Object) error {
-	hpa := &autoscalingv2.HorizontalPodAutoscaler{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      workload.GetName(),
-			Namespace: workload.GetNamespace(),
-		},
+// pauseReason 返回工作负载的HPA被暂停的原因，未暂停时返回空字符串
+// 依次检查工作负载的 hpa.infraflow.co/paused 注解、所在命名空间的 autoscale.infraflow.co/paused 标签和 GlobalPause
+func (r *AutoScaleReconciler) pauseReason(ctx context.Context, workload client.Object) (string, error) {
+	paused, err := kube.HPAPaused(workload)
+	if err != nil {
+		return "", err
+	}
+	if paused {
+		return kube.PausedByAnnotation, nil
+	}
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, client.ObjectKey{Name: workload.GetNamespace()}, ns); client.IgnoreNotFound(err) != nil {
+		return "", err
+	}
+	if paused, _ = strconv.ParseBool(ns.Labels[consts.PausedLabel]); paused {
+		return kube.PausedByNamespace, nil
+	}
+	if r.GlobalPause {
+		return kube.PausedGlobally, nil
+	}
+	return "", nil
+}
+
+// recordPaused 记录HPA被暂停的事件
+func (r *AutoScaleReconciler) recordPaused(workload client.Object, hpa *autoscalingv2.HorizontalPodAutoscaler, reason string) {
+	r.recordEvent(workload, hpa, corev1.EventTypeNormal, "HPAPaused", "Paused HorizontalPodAutoscaler %s at %d replicas (paused by %s)",
+		hpa.Name, hpa.Spec.MaxReplicas, reason)
+}
+
+// deleteHPA 删除Controller为工作负载创建的HPA，keep不为空时保留该名称的HPA
+// 从缓存中列出命名空间中的HPA，只删除由Controller为该工作负载管理（见 kube.IsManaged）且 scaleTargetRef
+// 指向该工作负载的HPA，包括失去 owner reference 但仍带有 managed-by 标签的HPA；
+// 用户手动创建的HPA保持不变；HPA名称模板修改后，之前名称的HPA同样由此删除
+func (r *AutoScaleReconciler) deleteHPA(ctx context.Context, workload client.Object, kind, keep string) error {
+	hpas := &autoscalingv2.HorizontalPodAutoscalerList{}
+	if err := r.List(ctx, hpas, client.InNamespace(workload.GetNamespace())); err != nil {
+		return err
+	}
+	for i := range hpas.Items {
+		hpa := &hpas.Items[i]
+		if hpa.Name == keep || !kube.IsManaged(hpa, workload) ||
+			hpa.Spec.ScaleTargetRef.Kind != kind || hpa.Spec.ScaleTargetRef.Name != workload.GetName() {
+			continue
+		}
+		if err := r.Delete(ctx, hpa); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		r.recordEvent(workload, hpa, corev1.EventTypeNormal, "HPADeleted", "Deleted HorizontalPodAutoscaler %s", hpa.Name)
 	}
-	return client.IgnoreNotFound(r.Delete(ctx, hpa))
+	return nil
 }
 
 // reconcileVPA 协调Vertical Pod Autoscaler
 // 1. 构建期望的VPA配置
 // 2. 检查现有VPA是否存在
-// 3. 创建新的VPA或更新现有的VPA
-// func (r *AutoScaleReconciler) reconcileVPA(ctx context.Context, workload client.Object, kind string) error {
-// 	desired, err := kube.BuildDesiredVPA(workload, kind)
-// 	if err != nil {
-// 		return err
-// 	}
-// 	current := &v1.VerticalPodAutoscaler{}
-// 	err = r.Get(ctx, client.ObjectKeyFromObject(desired), current)
-// 	if errors.IsNotFound(err) {
-// 		controllerutil.SetControllerReference(workload, desired, r.Scheme)
-// 		return r.Create(ctx, desired)
-// 	} else if err != nil {
-// 		return err
-// 	}
-// 	controllerutil.SetControllerReference(workload, current, r.Scheme)
-// 	if !kube.EqualVPA(current, desired) {
-// 		current.Spec = desired.Spec
-// 		return r.Update(ctx, current)
-// 	}
-// 	return nil
-// }
-
-// deleteVPA 删除与工作负载关联的VPA
-// func (r *AutoScaleReconciler) deleteVPA(ctx context.Context, workload client.Object) error {
-// 	vpa := &v1.VerticalPodAutoscaler{
-// 		ObjectMeta: metav1.ObjectMeta{
-// 			Name:      workload.GetName(),
-// 			Namespace: workload.GetNamespace(),
-// 		},
-// 	}
-// 	return client.IgnoreNotFound(r.Delete(ctx, vpa))
-// }
+// 3. 通过 server-side apply 创建或更新VPA，见 apply
+// 集群中的VPA CRD被卸载时跳过，不返回错误
+func (r *AutoScaleReconciler) reconcileVPA(ctx context.Context, workload client.Object, kind string, policy *autoscalev1alpha1.AutoscalePolicy) error {
+	spec, err := r.vpaSpec(ctx, workload, policy)
+	if err != nil {
+		return err
+	}
+	desired, err := kube.BuildDesiredVPA(workload, kind, spec)
+	if err != nil {
+		return err
+	}
+	if err := controllerutil.SetControllerReference(workload, desired, r.Scheme); err != nil {
+		return err
+	}
+	current := &vpav1.VerticalPodAutoscaler{}
+	err = r.Get(ctx, client.ObjectKeyFromObject(desired), current)
+	if meta.IsNoMatchError(err) {
+		log.FromContext(ctx).V(1).Info("VPA CRD is not installed, skip VPA reconciliation")
+		return nil
+	}
+	if errors.IsNotFound(err) {
+		if err := r.apply(ctx, workload, desired, false); err != nil {
+			return err
+		}
+		r.recordEvent(workload, desired, corev1.EventTypeNormal, "VPACreated", "Created VerticalPodAutoscaler %s", desired.Name)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := r.controllerConflict(workload, current, "VerticalPodAutoscaler", "VPAConflict"); err != nil {
+		return err
+	}
+	if metav1.IsControlledBy(current, workload) && kube.HasMetadata(current, desired) && kube.EqualVPA(current, desired) {
+		return nil
+	}
+
+	if err := r.upgradeManagedFields(ctx, current); err != nil {
+		return err
+	}
+	applied := desired.DeepCopy()
+	if err := r.apply(ctx, workload, applied, false); err != nil {
+		return err
+	}
+	diff := append(kube.DiffMetadata(current, desired), kube.DiffVPA(current, applied)...)
+	if applied.ResourceVersion != current.ResourceVersion && len(diff) > 0 {
+		r.recordEvent(workload, applied, corev1.EventTypeNormal, "VPAUpdated",
+			"Updated VerticalPodAutoscaler %s: %s", applied.Name, strings.Join(diff, ", "))
+	}
+	return nil
+}
+
+// apply 通过 server-side apply 写入HPA/VPA，field manager 为 infraflow-autoscale
+// 只有期望配置中设置的字段归Controller所有，其他写入方（例如 GitOps 工具）设置的其他字段保持不变
+// 与其他 field manager 的字段冲突时，开启 ForceOwnership 或 force 为true 才会强制获取字段的所有权，
+// 否则记录冲突事件并返回 conflictError；其他 Conflict 错误（例如并发写入）原样返回，由controller-runtime重新入队
+func (r *AutoScaleReconciler) apply(ctx context.Context, workload, obj client.Object, force bool) error {
+	gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+	if err != nil {
+		return err
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	opts := []client.PatchOption{client.FieldOwner(consts.FieldManager)}
+	if force || r.ForceOwnership {
+		opts = append(opts, client.ForceOwnership)
+	}
+	err = r.Patch(ctx, obj, client.Apply, opts...)
+	if !errors.IsConflict(err) || !errors.HasStatusCause(err, metav1.CauseTypeFieldManagerConflict) {
+		return err
+	}
+	reason := "HPAConflict"
+	if _, ok := obj.(*vpav1.VerticalPodAutoscaler); ok {
+		reason = "VPAConflict"
+	}
+	conflict := &conflictError{message: fmt.Sprintf(
+		"%s %s has fields managed by other field managers, start the controller with --force-ownership to take them over: %v",
+		gvk.Kind, obj.GetName(), err)}
+	r.recordEvent(workload, obj, corev1.EventTypeWarning, reason, "%s", conflict.Error())
+	return conflict
+}
+
+// controllerConflict 同名的HPA/VPA由其他对象控制时记录冲突事件并返回 conflictError，否则返回nil
+// 例如 Deployment api-statefulset 与 StatefulSet api 的HPA/VPA名称相同（见 kube.AutoscalerName），
+// 先创建的一方保留所有权，通过 hpa.infraflow.co/adopt 也不能接管
+func (r *AutoScaleReconciler) controllerConflict(workload, obj client.Object, kind, reason string) error {
+	owner := metav1.GetControllerOf(obj)
+	if owner == nil || owner.UID == workload.GetUID() {
+		return nil
+	}
+	conflict := &conflictError{message: fmt.Sprintf("%s %s is controlled by %s %s", kind, obj.GetName(), owner.Kind, owner.Name)}
+	r.recordEvent(workload, obj, corev1.EventTypeWarning, reason, "%s", conflict.Error())
+	return conflict
+}
+
+// legacyFieldManagers 改用 server-side apply 之前，Controller 通过 Create/Update 写入HPA/VPA时的 field manager，
+// 由可执行文件名决定（镜像中为 manager，通过 go run 运行时为 main）
+var legacyFieldManagers = sets.New("manager", "main")
+
+// upgradeManagedFields 将旧版本Controller通过 Create/Update 获得的字段所有权转移给 infraflow-autoscale，
+// 避免升级后第一次 apply 时与自身之前的写入冲突，没有需要转移的字段时不访问API Server
+func (r *AutoScaleReconciler) upgradeManagedFields(ctx context.Context, obj client.Object) error {
+	patch, err := csaupgrade.UpgradeManagedFieldsPatch(obj, legacyFieldManagers, consts.FieldManager)
+	if err != nil || patch == nil {
+		return err
+	}
+	return r.Patch(ctx, obj, client.RawPatch(types.JSONPatchType, patch))
+}
+
+// deleteVPA 删除与工作负载关联的VPA，手动创建或由其他工作负载控制的同名VPA保持不变（见 kube.IsManaged）
+// 先从缓存中读取，VPA不存在或集群中未安装VPA CRD时不访问API Server
+func (r *AutoScaleReconciler) deleteVPA(ctx context.Context, workload client.Object, kind string) error {
+	vpa := &vpav1.VerticalPodAutoscaler{}
+	key := client.ObjectKey{Namespace: workload.GetNamespace(), Name: kube.AutoscalerName(workload.GetName(), kind)}
+	err := r.Get(ctx, key, vpa)
+	if err == nil && kube.IsManaged(vpa, workload) {
+		if err = r.Delete(ctx, vpa); err == nil {
+			r.recordEvent(workload, vpa, corev1.EventTypeNormal, "VPADeleted", "Deleted VerticalPodAutoscaler %s", vpa.Name)
+		}
+	}
+	if meta.IsNoMatchError(err) {
+		return nil
+	}
+	return client.IgnoreNotFound(err)
+}
+
+// recordEvent 在工作负载上记录事件，开启 AutoscalerEvents 时同时记录在对应的HPA/VPA上
+func (r *AutoScaleReconciler) recordEvent(workload, autoscaler client.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Event.Eventf(workload, eventtype, reason, messageFmt, args...)
+	if r.AutoscalerEvents && autoscaler != nil {
+		r.Event.Eventf(autoscaler, eventtype, reason, messageFmt, args...)
+	}
+}
+
+// validationErrors 如果err是注解校验错误，将其中的错误追加到errs并返回true
+func validationErrors(err error, errs *field.ErrorList) bool {
+	var invalid *kube.ValidationError
+	if !stderrors.As(err, &invalid) {
+		return false
+	}
+	*errs = append(*errs, invalid.Errors...)
+	return true
+}
+
+// conflictError 同名HPA已存在且不能由Controller管理，或HPA/VPA的字段由其他 field manager 管理，需要用户处理，不重新入队
+type conflictError struct {
+	message string
+}
+
+func (e *conflictError) Error() string {
+	return e.message
+}
+
+// reportValidation 报告工作负载注解的校验结果，存在不合法的注解时记录Warning事件，返回汇总的错误信息
+func (r *AutoScaleReconciler) reportValidation(workload client.Object, errs field.ErrorList) string {
+	if len(errs) == 0 {
+		return ""
+	}
+	message := (&kube.ValidationError{Errors: errs}).Error()
+	r.Event.Event(workload, corev1.EventTypeWarning, "InvalidAnnotations", message)
+	return message
+}
+
+// reportStatus 将协调结果写入工作负载的状态注解，两个注解通过一次Patch更新
+// 协调返回错误时记录 ReconcileFailed Warning事件，更新冲突（Conflict）会立即重试，不记录事件
+// status.infraflow.co/validation 记录不合法注解的错误信息，所有注解合法时移除
+// status.infraflow.co/autoscale 记录自动扩缩容状态，lastError 依次取协调返回的错误、注解校验错误和HPA冲突，
+// 工作负载不再被管理时移除；除 lastReconcileTime 外没有变化时保留原值，避免每次协调都更新工作负载
+func (r *AutoScaleReconciler) reportStatus(ctx context.Context, workload client.Object, managed bool,
+	status *kube.AutoscaleStatus, invalid field.ErrorList, reconcileErr error) error {
+	validation := r.reportValidation(workload, invalid)
+	if reconcileErr != nil && !errors.IsConflict(reconcileErr) {
+		r.Event.Event(workload, corev1.EventTypeWarning, "ReconcileFailed", reconcileErr.Error())
+	}
+
+	autoscale := ""
+	if managed {
+		switch {
+		case reconcileErr != nil:
+			status.LastError = reconcileErr.Error()
+		case validation != "":
+			status.LastError = validation
+		}
+		autoscale = workload.GetAnnotations()[consts.StatusAutoscale]
+		if current, err := kube.ParseAutoscaleStatus(autoscale); err != nil || !kube.EqualAutoscaleStatus(current, status) {
+			status.LastReconcileTime = metav1.NewTime(r.now().Truncate(time.Second))
+			autoscale = status.String()
+		}
+	}
+
+	return r.setStatusAnnotations(ctx, workload, map[string]string{
+		consts.StatusValidation: validation,
+		consts.StatusAutoscale:  autoscale,
+	})
+}
+
+// observedAnnotationsHash 返回上次协调时记录在 status.infraflow.co/autoscale 中的注解哈希值，没有记录时返回空字符串
+func observedAnnotationsHash(workload client.Object) string {
+	status, err := kube.ParseAutoscaleStatus(workload.GetAnnotations()[consts.StatusAutoscale])
+	if err != nil {
+		return ""
+	}
+	return status.ObservedAnnotationsHash
+}
+
+// setStatusAnnotations 更新工作负载上的状态注解，值为空时移除对应的注解，所有值都未变化时不访问API Server
+func (r *AutoScaleReconciler) setStatusAnnotations(ctx context.Context, workload client.Object, values map[string]string) error {
+	annotations := workload.GetAnnotations()
+	changed := false
+	for key, value := range values {
+		if current, ok := annotations[key]; ok != (value != "") || current != value {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	patch := client.MergeFrom(workload.DeepCopyObject().(client.Object))
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	for key, value := range values {
+		if value == "" {
+			delete(annotations, key)
+		} else {
+			annotations[key] = value
+		}
+	}
+	workload.SetAnnotations(annotations)
+	return r.Patch(ctx, workload, patch)
+}
 
 // shouldManageHPA 检查工作负载的注解是否包含HPA相关的配置
+// hpa.infraflow.co/adopt、hpa.infraflow.co/nameTemplate 只控制如何管理HPA，单独设置时不会创建HPA（见 consts.IsHPAOption）
 // 支持的注解前缀：
 // - hpa.infraflow.co/
+// - prometheus.hpa.infraflow.co/
+// - pods.hpa.infraflow.co/
+// - object.hpa.infraflow.co/
 func (r *AutoScaleReconciler) shouldManageHPA(annotations map[string]string) bool {
 	for key := range annotations {
-		if strings.HasPrefix(key, "hpa.infraflow.co/") {
+		if consts.IsHPAAnnotation(key) && !consts.IsHPAOption(key) {
 			return true
 		}
 	}
@@ -217,47 +768,193 @@ func (r *AutoScaleReconciler) shouldManageHPA(annotations map[string]string) boo
 // shouldManageVPA 检查工作负载的注解是否包含VPA相关的配置
 // 支持的注解前缀：
 // - vpa.infraflow.co/
-// func (r *AutoScaleReconciler) shouldManageVPA(annotations map[string]string) bool {
-// 	for key := range annotations {
-// 		if strings.HasPrefix(key, "vpa.infraflow.co/") {
-// 			return true
-// 		}
-// 	}
-// 	return false
-// }
+func (r *AutoScaleReconciler) shouldManageVPA(annotations map[string]string) bool {
+	for key := range annotations {
+		if consts.IsVPAAnnotation(key) {
+			return true
+		}
+	}
+	return false
+}
 
+// SetupWithManager 为每种工作负载类型注册一个controller
 func (r *AutoScaleReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&appsv1.Deployment{}).
-		Watches(&appsv1.StatefulSet{}, handler.EnqueueRequestsFromMapFunc(r.findObjectsForStatefulSet)).
-		Watches(&appsv1.DaemonSet{}, handler.EnqueueRequestsFromMapFunc(r.findObjectsForDaemonSet)).
-		Owns(&autoscalingv2.HorizontalPodAutoscaler{}).
-		//Owns(&v1.VerticalPodAutoscaler{}).
-		Complete(r)
-}
-
-// findObjectsForStatefulSet 为 StatefulSet 查找关联的对象
-func (r *AutoScaleReconciler) findObjectsForStatefulSet(ctx context.Context, obj client.Object) []reconcile.Request {
-	sts := obj.(*appsv1.StatefulSet)
-	return []reconcile.Request{
-		{
-			NamespacedName: types.NamespacedName{
-				Name:      sts.Name,
-				Namespace: sts.Namespace,
-			},
-		},
-	}
-}
-
-// findObjectsForDaemonSet 为 DaemonSet 查找关联的对象
-func (r *AutoScaleReconciler) findObjectsForDaemonSet(ctx context.Context, obj client.Object) []reconcile.Request {
-	ds := obj.(*appsv1.DaemonSet)
-	return []reconcile.Request{
-		{
-			NamespacedName: types.NamespacedName{
-				Name:      ds.Name,
-				Namespace: ds.Namespace,
-			},
-		},
+	if r.EnableVPA {
+		installed, err := vpaInstalled(mgr.GetRESTMapper())
+		if err != nil {
+			return err
+		}
+		if !installed {
+			mgr.GetLogger().Info("VPA CRD is not installed in the cluster, VPA management is disabled")
+			r.EnableVPA = false
+		}
+	}
+
+	for _, kind := range kube.WorkloadKinds {
+		if err := mgr.GetFieldIndexer().IndexField(context.Background(), kube.NewWorkload(kind), profileIndexField,
+			annotationIndexer(consts.HPAProfile)); err != nil {
+			return err
+		}
+		if err := mgr.GetFieldIndexer().IndexField(context.Background(), kube.NewWorkload(kind), calendarIndexField,
+			annotationIndexer(consts.HPACalendar)); err != nil {
+			return err
+		}
+
+		capabilities := kube.CapabilitiesOf(kind)
+		b := ctrl.NewControllerManagedBy(mgr).
+			Named(strings.ToLower(kind)).
+			For(kube.NewWorkload(kind), builder.WithPredicates(workloadPredicate()))
+		if capabilities.HPA {
+			// HPA 的 status 每个同步周期都会更新，只关注 spec 变化和删除
+			b = b.Owns(&autoscalingv2.HorizontalPodAutoscaler{}, builder.WithPredicates(predicate.GenerationChangedPredicate{}))
+		}
+		if r.EnableVPA && capabilities.VPA {
+			b = b.Owns(&vpav1.VerticalPodAutoscaler{}, builder.WithPredicates(predicate.GenerationChangedPredicate{}))
+		}
+		if capabilities.HPA {
+			b = b.Watches(&autoscalev1alpha1.ClusterAutoscaleProfile{}, handler.EnqueueRequestsFromMapFunc(r.workloadsReferencingProfile(kind)),
+				builder.WithPredicates(predicate.GenerationChangedPredicate{}))
+			b = b.Watches(&autoscalev1alpha1.ClusterAutoscaleCalendar{}, handler.EnqueueRequestsFromMapFunc(r.workloadsReferencingCalendar(kind)),
+				builder.WithPredicates(predicate.GenerationChangedPredicate{}))
+			b = b.Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.workloadsInNamespace(kind)),
+				builder.WithPredicates(namespacePausePredicate()))
+		}
+		b = b.Watches(&autoscalev1alpha1.AutoscalePolicy{}, handler.EnqueueRequestsFromMapFunc(r.workloadsInPolicyNamespace(kind)),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}))
+		if err := b.Complete(r.forKind(kind)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// annotationIndexer 返回按注解的值索引工作负载的函数，注解不存在或为空时不索引
+func annotationIndexer(key string) client.IndexerFunc {
+	return func(obj client.Object) []string {
+		if name := obj.GetAnnotations()[key]; name != "" {
+			return []string{name}
+		}
+		return nil
+	}
+}
+
+// workloadsInPolicyNamespace 返回将 AutoscalePolicy 的变化映射为工作负载协调请求的函数
+// 策略的 targetRef 或 selector 变化、策略被删除时，之前选中的工作负载也需要重新协调，
+// 因此将策略所在命名空间中该类型的所有工作负载重新入队
+func (r *AutoScaleReconciler) workloadsInPolicyNamespace(kind string) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		list := kube.NewWorkloadList(kind)
+		if err := r.List(ctx, list, client.InNamespace(obj.GetNamespace())); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to list workloads for AutoscalePolicy", "policy", obj.GetName(), "kind", kind)
+			return nil
+		}
+		return requestsFor(list)
+	}
+}
+
+// workloadsInNamespace 返回将命名空间的变化映射为其中该类型所有工作负载协调请求的函数
+func (r *AutoScaleReconciler) workloadsInNamespace(kind string) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		list := kube.NewWorkloadList(kind)
+		if err := r.List(ctx, list, client.InNamespace(obj.GetName())); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to list workloads for Namespace", "namespace", obj.GetName(), "kind", kind)
+			return nil
+		}
+		return requestsFor(list)
+	}
+}
+
+// workloadsReferencingProfile 返回将 ClusterAutoscaleProfile 的变化映射为工作负载协调请求的函数，
+// 通过字段索引找到所有引用该配置的工作负载
+func (r *AutoScaleReconciler) workloadsReferencingProfile(kind string) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		list := kube.NewWorkloadList(kind)
+		if err := r.List(ctx, list, client.MatchingFields{profileIndexField: obj.GetName()}); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to list workloads for ClusterAutoscaleProfile", "profile", obj.GetName(), "kind", kind)
+			return nil
+		}
+		return requestsFor(list)
+	}
+}
+
+// workloadsReferencingCalendar 返回将 ClusterAutoscaleCalendar 的变化映射为工作负载协调请求的函数，
+// 包括通过注解引用该日历的工作负载、引用该日历的 AutoscalePolicy 所在命名空间中的工作负载，
+// 以及引用了（引用该日历的）ClusterAutoscaleProfile 的工作负载
+func (r *AutoScaleReconciler) workloadsReferencingCalendar(kind string) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		logger := log.FromContext(ctx).WithValues("calendar", obj.GetName(), "kind", kind)
+		seen := sets.New[types.NamespacedName]()
+		var requests []reconcile.Request
+		add := func(list client.ObjectList) {
+			for _, req := range requestsFor(list) {
+				if !seen.Has(req.NamespacedName) {
+					seen.Insert(req.NamespacedName)
+					requests = append(requests, req)
+				}
+			}
+		}
+
+		list := kube.NewWorkloadList(kind)
+		if err := r.List(ctx, list, client.MatchingFields{calendarIndexField: obj.GetName()}); err != nil {
+			logger.Error(err, "Failed to list workloads for ClusterAutoscaleCalendar")
+			return nil
+		}
+		add(list)
+
+		policies := &autoscalev1alpha1.AutoscalePolicyList{}
+		if err := r.List(ctx, policies); err != nil {
+			logger.Error(err, "Failed to list AutoscalePolicies for ClusterAutoscaleCalendar")
+			return nil
+		}
+		for _, policy := range policies.Items {
+			if policy.Spec.HPA == nil || policy.Spec.HPA.Calendar != obj.GetName() {
+				continue
+			}
+			list := kube.NewWorkloadList(kind)
+			if err := r.List(ctx, list, client.InNamespace(policy.Namespace)); err != nil {
+				logger.Error(err, "Failed to list workloads for AutoscalePolicy", "policy", policy.Name)
+				return nil
+			}
+			add(list)
+		}
+
+		profiles := &autoscalev1alpha1.ClusterAutoscaleProfileList{}
+		if err := r.List(ctx, profiles); err != nil {
+			logger.Error(err, "Failed to list ClusterAutoscaleProfiles for ClusterAutoscaleCalendar")
+			return nil
+		}
+		for _, profile := range profiles.Items {
+			if profile.Spec.HPA.Calendar != obj.GetName() {
+				continue
+			}
+			list := kube.NewWorkloadList(kind)
+			if err := r.List(ctx, list, client.MatchingFields{profileIndexField: profile.Name}); err != nil {
+				logger.Error(err, "Failed to list workloads for ClusterAutoscaleProfile", "profile", profile.Name)
+				return nil
+			}
+			add(list)
+		}
+		return requests
+	}
+}
+
+// requestsFor 返回列表中每个工作负载的协调请求
+func requestsFor(list client.ObjectList) []reconcile.Request {
+	var requests []reconcile.Request
+	_ = meta.EachListItem(list, func(item runtime.Object) error {
+		if workload, ok := item.(client.Object); ok {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(workload)})
+		}
+		return nil
+	})
+	return requests
+}
+
+// vpaInstalled 检查集群中是否安装了VPA CRD
+func vpaInstalled(mapper meta.RESTMapper) (bool, error) {
+	_, err := mapper.RESTMapping(vpav1.GroupVersion.WithKind("VerticalPodAutoscaler").GroupKind(), vpav1.GroupVersion.Version)
+	if meta.IsNoMatchError(err) {
+		return false, nil
 	}
+	return err == nil, err
 }
@@ -18,24 +18,31 @@ package controller
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	autoscalev1alpha1 "github.com/infraflows/autoscale-controller/pkg/apis/autoscale/v1alpha1"
+	vpav1 "github.com/infraflows/autoscale-controller/pkg/apis/vpa/v1"
 	"github.com/infraflows/autoscale-controller/pkg/consts"
+	"github.com/infraflows/autoscale-controller/pkg/kube"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	clocktesting "k8s.io/utils/clock/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 var _ = Describe("AutoScale Controller", func() {
-	const (
-		timeout  = time.Second * 10
-		interval = time.Millisecond * 250
-	)
-
 	Context("When reconciling a Deployment with HPA annotations", func() {
 		var (
 			ctx            context.Context
@@ -55,7 +62,8 @@ var _ = Describe("AutoScale Controller", func() {
 					Name: namespace,
 				},
 			}
-			Expect(k8sClient.Create(ctx, ns)).Should(Succeed())
+			// 命名空间在用例之间复用：envtest 中没有 namespace controller，删除后会一直处于 Terminating 状态
+			Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, ns))).Should(Succeed())
 
 			// 创建测试 Deployment
 			deployment = &appsv1.Deployment{
@@ -95,9 +103,12 @@ var _ = Describe("AutoScale Controller", func() {
 		})
 
 		AfterEach(func() {
-			// 清理测试资源
+			// 清理测试资源，等待 finalizer 被移除后 Deployment 彻底删除
 			Expect(k8sClient.Delete(ctx, deployment)).Should(Succeed())
-			Expect(k8sClient.Delete(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})).Should(Succeed())
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(deployment), &appsv1.Deployment{}))
+			}, timeout, interval).Should(BeTrue())
+			// 命名空间在用例之间复用，不做删除
 		})
 
 		It("Should create HPA when deployment has HPA annotations", func() {
@@ -126,18 +137,30 @@ var _ = Describe("AutoScale Controller", func() {
 
 		It("Should update HPA when deployment annotations change", func() {
 			// 更新 Deployment 注解
-			Eventually(func() error {
+			updateDeployment(k8sClient, deployment, func(d *appsv1.Deployment) {
+				d.Annotations[consts.HPAMaxReplicas] = "15"
+			})
+
+			// 验证 HPA 更新
+			Eventually(func() int32 {
+				hpa := &autoscalingv2.HorizontalPodAutoscaler{}
 				if err := k8sClient.Get(ctx, types.NamespacedName{
 					Name:      deploymentName,
 					Namespace: namespace,
-				}, deployment); err != nil {
-					return err
+				}, hpa); err != nil {
+					return 0
 				}
-				deployment.Annotations[consts.HPAMaxReplicas] = "15"
-				return k8sClient.Update(ctx, deployment)
-			}, timeout, interval).Should(Succeed())
+				return hpa.Spec.MaxReplicas
+			}, timeout, interval).Should(Equal(int32(15)))
+		})
+
+		It("Should honor deprecated annotation aliases", func() {
+			// 使用旧文档中的写法配置最大副本数
+			updateDeployment(k8sClient, deployment, func(d *appsv1.Deployment) {
+				delete(d.Annotations, consts.HPAMaxReplicas)
+				d.Annotations["hpa.infraflow.co/cpu.maxReplicas"] = "12"
+			})
 
-			// 验证 HPA 更新
 			Eventually(func() int32 {
 				hpa := &autoscalingv2.HorizontalPodAutoscaler{}
 				if err := k8sClient.Get(ctx, types.NamespacedName{
@@ -147,23 +170,176 @@ var _ = Describe("AutoScale Controller", func() {
 					return 0
 				}
 				return hpa.Spec.MaxReplicas
-			}, timeout, interval).Should(Equal(int32(15)))
+			}, timeout, interval).Should(Equal(int32(12)))
+
+			// 同时配置新旧写法时，以新写法为准
+			updateDeployment(k8sClient, deployment, func(d *appsv1.Deployment) {
+				d.Annotations[consts.HPAMaxReplicas] = "20"
+			})
+
+			Eventually(func() int32 {
+				hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+				if err := k8sClient.Get(ctx, types.NamespacedName{
+					Name:      deploymentName,
+					Namespace: namespace,
+				}, hpa); err != nil {
+					return 0
+				}
+				return hpa.Spec.MaxReplicas
+			}, timeout, interval).Should(Equal(int32(20)))
 		})
 
-		It("Should delete HPA when deployment annotations are removed", func() {
-			// 移除 HPA 注解
+		It("Should set scaling behavior from scaleUp/scaleDown annotations", func() {
+			updateDeployment(k8sClient, deployment, func(d *appsv1.Deployment) {
+				d.Annotations[consts.HPAScaleDownStabilizationWindowSeconds] = "600"
+				d.Annotations[consts.HPAScaleDownPolicies] = `[{"type":"Percent","value":10,"periodSeconds":60}]`
+				d.Annotations[consts.HPAScaleUpSelectPolicy] = "Min"
+			})
+
+			// 验证 HPA behavior
+			Eventually(func(g Gomega) {
+				hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{
+					Name:      deploymentName,
+					Namespace: namespace,
+				}, hpa)).Should(Succeed())
+				g.Expect(hpa.Spec.Behavior).ShouldNot(BeNil())
+				g.Expect(hpa.Spec.Behavior.ScaleDown.StabilizationWindowSeconds).Should(HaveValue(Equal(int32(600))))
+				g.Expect(hpa.Spec.Behavior.ScaleDown.Policies).Should(Equal([]autoscalingv2.HPAScalingPolicy{
+					{Type: autoscalingv2.PercentScalingPolicy, Value: 10, PeriodSeconds: 60},
+				}))
+				g.Expect(hpa.Spec.Behavior.ScaleUp.SelectPolicy).Should(HaveValue(Equal(autoscalingv2.MinChangePolicySelect)))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("Should add Prometheus external metrics from annotations", func() {
+			updateDeployment(k8sClient, deployment, func(d *appsv1.Deployment) {
+				d.Annotations[consts.PrometheusMetricName] = "http_requests_total"
+				d.Annotations[consts.PrometheusMetricSelector] = "service=api"
+				d.Annotations[consts.PrometheusTargetAverageValue] = "100"
+				d.Annotations[consts.IndexedKey(consts.PrometheusMetricName, 1)] = "queue_messages_ready"
+				d.Annotations[consts.IndexedKey(consts.PrometheusTargetValue, 1)] = "500"
+			})
+
+			// 验证 HPA External Metrics
+			Eventually(func(g Gomega) {
+				hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{
+					Name:      deploymentName,
+					Namespace: namespace,
+				}, hpa)).Should(Succeed())
+				g.Expect(hpa.Spec.Metrics).Should(HaveLen(3))
+
+				external := hpa.Spec.Metrics[1].External
+				g.Expect(external).ShouldNot(BeNil())
+				g.Expect(external.Metric.Name).Should(Equal("http_requests_total"))
+				g.Expect(external.Metric.Selector.MatchLabels).Should(HaveKeyWithValue("service", "api"))
+				g.Expect(external.Target.Type).Should(Equal(autoscalingv2.AverageValueMetricType))
+				g.Expect(external.Target.AverageValue.String()).Should(Equal("100"))
+
+				external = hpa.Spec.Metrics[2].External
+				g.Expect(external).ShouldNot(BeNil())
+				g.Expect(external.Metric.Name).Should(Equal("queue_messages_ready"))
+				g.Expect(external.Target.Type).Should(Equal(autoscalingv2.ValueMetricType))
+				g.Expect(external.Target.Value.String()).Should(Equal("500"))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("Should add Pods and Object custom metrics from annotations", func() {
+			updateDeployment(k8sClient, deployment, func(d *appsv1.Deployment) {
+				d.Annotations[consts.PodsMetricName] = "queue_depth"
+				d.Annotations[consts.PodsTargetAverageValue] = "30"
+				d.Annotations[consts.ObjectMetricName] = "requests_per_second"
+				d.Annotations[consts.ObjectMetricSelector] = "path=/api"
+				d.Annotations[consts.ObjectTargetValue] = "2k"
+				d.Annotations[consts.ObjectDescribedObjectAPIVersion] = "networking.k8s.io/v1"
+				d.Annotations[consts.ObjectDescribedObjectKind] = "Ingress"
+				d.Annotations[consts.ObjectDescribedObjectName] = "main-route"
+			})
+
+			// 验证 HPA Pods/Object 指标
+			Eventually(func(g Gomega) {
+				hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{
+					Name:      deploymentName,
+					Namespace: namespace,
+				}, hpa)).Should(Succeed())
+				g.Expect(hpa.Spec.Metrics).Should(HaveLen(3))
+
+				pods := hpa.Spec.Metrics[1].Pods
+				g.Expect(pods).ShouldNot(BeNil())
+				g.Expect(pods.Metric.Name).Should(Equal("queue_depth"))
+				g.Expect(pods.Target.AverageValue.String()).Should(Equal("30"))
+
+				object := hpa.Spec.Metrics[2].Object
+				g.Expect(object).ShouldNot(BeNil())
+				g.Expect(object.Metric.Name).Should(Equal("requests_per_second"))
+				g.Expect(object.Metric.Selector.MatchLabels).Should(HaveKeyWithValue("path", "/api"))
+				g.Expect(object.DescribedObject).Should(Equal(autoscalingv2.CrossVersionObjectReference{
+					APIVersion: "networking.k8s.io/v1",
+					Kind:       "Ingress",
+					Name:       "main-route",
+				}))
+				g.Expect(object.Target.Type).Should(Equal(autoscalingv2.ValueMetricType))
+				g.Expect(object.Target.Value.String()).Should(Equal("2k"))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("Should add container resource metrics for an existing container", func() {
+			updateDeployment(k8sClient, deployment, func(d *appsv1.Deployment) {
+				delete(d.Annotations, consts.HPACpuTargetAverageUtilization)
+				d.Annotations[consts.ContainerKey("nginx", consts.HPACpuTargetAverageUtilization)] = "60"
+			})
+
+			// 验证 HPA 只包含容器级别的 CPU 指标
+			Eventually(func(g Gomega) {
+				hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{
+					Name:      deploymentName,
+					Namespace: namespace,
+				}, hpa)).Should(Succeed())
+				g.Expect(hpa.Spec.Metrics).Should(HaveLen(1))
+				g.Expect(hpa.Spec.Metrics[0].Type).Should(Equal(autoscalingv2.ContainerResourceMetricSourceType))
+				g.Expect(hpa.Spec.Metrics[0].ContainerResource.Container).Should(Equal("nginx"))
+				g.Expect(hpa.Spec.Metrics[0].ContainerResource.Name).Should(Equal(corev1.ResourceCPU))
+				g.Expect(hpa.Spec.Metrics[0].ContainerResource.Target.AverageUtilization).Should(HaveValue(Equal(int32(60))))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("Should not apply container resource metrics for an unknown container", func() {
+			// 等待 HPA 创建
 			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{
+					Name:      deploymentName,
+					Namespace: namespace,
+				}, &autoscalingv2.HorizontalPodAutoscaler{})
+			}, timeout, interval).Should(Succeed())
+
+			updateDeployment(k8sClient, deployment, func(d *appsv1.Deployment) {
+				d.Annotations[consts.HPAMaxReplicas] = "15"
+				d.Annotations[consts.ContainerKey("envoy", consts.HPACpuTargetAverageUtilization)] = "60"
+			})
+
+			// 验证 HPA 未被更新
+			Consistently(func() int32 {
+				hpa := &autoscalingv2.HorizontalPodAutoscaler{}
 				if err := k8sClient.Get(ctx, types.NamespacedName{
 					Name:      deploymentName,
 					Namespace: namespace,
-				}, deployment); err != nil {
-					return err
+				}, hpa); err != nil {
+					return 0
 				}
-				delete(deployment.Annotations, consts.HPAMinReplicas)
-				delete(deployment.Annotations, consts.HPAMaxReplicas)
-				delete(deployment.Annotations, consts.HPACpuTargetAverageUtilization)
-				return k8sClient.Update(ctx, deployment)
-			}, timeout, interval).Should(Succeed())
+				return hpa.Spec.MaxReplicas
+			}, time.Second*3, interval).Should(Equal(int32(10)))
+		})
+
+		It("Should delete HPA when deployment annotations are removed", func() {
+			// 移除 HPA 注解
+			updateDeployment(k8sClient, deployment, func(d *appsv1.Deployment) {
+				delete(d.Annotations, consts.HPAMinReplicas)
+				delete(d.Annotations, consts.HPAMaxReplicas)
+				delete(d.Annotations, consts.HPACpuTargetAverageUtilization)
+			})
 
 			// 验证 HPA 被删除
 			Eventually(func() bool {
@@ -176,4 +352,1655 @@ var _ = Describe("AutoScale Controller", func() {
 			}, timeout, interval).Should(BeTrue())
 		})
 	})
+
+	Context("When reconciling a Deployment with VPA annotations", func() {
+		var (
+			ctx            context.Context
+			deployment     *appsv1.Deployment
+			namespace      string
+			deploymentName string
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			namespace = "test-vpa-namespace"
+			deploymentName = "test-vpa-deployment"
+
+			ns := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: namespace,
+				},
+			}
+			Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, ns))).Should(Succeed())
+
+			deployment = &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      deploymentName,
+					Namespace: namespace,
+					Annotations: map[string]string{
+						consts.VPAUpdateMode:     "Initial",
+						consts.VPAResourcePolicy: `{"containerPolicies":[{"containerName":"*","minAllowed":{"cpu":"100m"}}]}`,
+					},
+				},
+				Spec: appsv1.DeploymentSpec{
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"app": deploymentName,
+						},
+					},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{
+								"app": deploymentName,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:  "nginx",
+									Image: "nginx:1.14.2",
+								},
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, deployment)).Should(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, deployment)).Should(Succeed())
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(deployment), &appsv1.Deployment{}))
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("Should create VPA when deployment has VPA annotations", func() {
+			Eventually(func(g Gomega) {
+				vpa := &vpav1.VerticalPodAutoscaler{}
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{
+					Name:      deploymentName,
+					Namespace: namespace,
+				}, vpa)).Should(Succeed())
+				g.Expect(vpa.Spec.TargetRef.Kind).Should(Equal("Deployment"))
+				g.Expect(vpa.Spec.TargetRef.Name).Should(Equal(deploymentName))
+				g.Expect(vpa.Spec.UpdatePolicy.UpdateMode).Should(HaveValue(Equal(vpav1.UpdateModeInitial)))
+				g.Expect(vpa.Spec.ResourcePolicy.ContainerPolicies).Should(HaveLen(1))
+				g.Expect(vpa.Spec.ResourcePolicy.ContainerPolicies[0].MinAllowed.Cpu().String()).Should(Equal("100m"))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("Should merge containerPolicies and min/max shorthand annotations into the resource policy", func() {
+			updateDeployment(k8sClient, deployment, func(d *appsv1.Deployment) {
+				d.Annotations[consts.VPAContainerPolicy] = `[{"containerName":"nginx","maxAllowed":{"memory":"1Gi"}}]`
+				d.Annotations[consts.VPACpuMinAllowed] = "200m"
+				d.Annotations[consts.VPAMemoryMaxAllowed] = "4Gi"
+			})
+
+			Eventually(func(g Gomega) {
+				vpa := &vpav1.VerticalPodAutoscaler{}
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{
+					Name:      deploymentName,
+					Namespace: namespace,
+				}, vpa)).Should(Succeed())
+				g.Expect(vpa.Spec.ResourcePolicy).ShouldNot(BeNil())
+
+				policies := vpa.Spec.ResourcePolicy.ContainerPolicies
+				g.Expect(policies).Should(HaveLen(2))
+				// 通配策略：简写注解覆盖 resourcePolicy 中的 cpu 下限
+				g.Expect(policies[0].ContainerName).Should(Equal(vpav1.DefaultContainerResourcePolicy))
+				g.Expect(policies[0].MinAllowed.Cpu().String()).Should(Equal("200m"))
+				g.Expect(policies[0].MaxAllowed.Memory().String()).Should(Equal("4Gi"))
+				// containerPolicies 追加的容器策略
+				g.Expect(policies[1].ContainerName).Should(Equal("nginx"))
+				g.Expect(policies[1].MaxAllowed.Memory().String()).Should(Equal("1Gi"))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("Should update VPA when deployment annotations change", func() {
+			updateDeployment(k8sClient, deployment, func(d *appsv1.Deployment) {
+				d.Annotations[consts.VPAUpdateMode] = "Off"
+			})
+
+			Eventually(func() vpav1.UpdateMode {
+				vpa := &vpav1.VerticalPodAutoscaler{}
+				if err := k8sClient.Get(ctx, types.NamespacedName{
+					Name:      deploymentName,
+					Namespace: namespace,
+				}, vpa); err != nil || vpa.Spec.UpdatePolicy == nil || vpa.Spec.UpdatePolicy.UpdateMode == nil {
+					return ""
+				}
+				return *vpa.Spec.UpdatePolicy.UpdateMode
+			}, timeout, interval).Should(Equal(vpav1.UpdateModeOff))
+		})
+
+		It("Should delete VPA when deployment annotations are removed", func() {
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{
+					Name:      deploymentName,
+					Namespace: namespace,
+				}, &vpav1.VerticalPodAutoscaler{})
+			}, timeout, interval).Should(Succeed())
+
+			updateDeployment(k8sClient, deployment, func(d *appsv1.Deployment) {
+				delete(d.Annotations, consts.VPAUpdateMode)
+				delete(d.Annotations, consts.VPAResourcePolicy)
+			})
+
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, types.NamespacedName{
+					Name:      deploymentName,
+					Namespace: namespace,
+				}, &vpav1.VerticalPodAutoscaler{}))
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+
+	Context("When a Deployment has invalid HPA annotations", func() {
+		const (
+			deploymentName = "test-invalid-deployment"
+			namespace      = "test-validation-namespace"
+		)
+
+		var deployment *appsv1.Deployment
+
+		BeforeEach(func() {
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+			Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, ns))).Should(Succeed())
+
+			replicas := int32(1)
+			labels := map[string]string{"app": deploymentName}
+			deployment = &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      deploymentName,
+					Namespace: namespace,
+					Annotations: map[string]string{
+						consts.HPAMaxReplicas:                 "ten",
+						consts.HPACpuTargetAverageUtilization: "high",
+					},
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Selector: &metav1.LabelSelector{MatchLabels: labels},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: labels},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "nginx", Image: "nginx:latest"}},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, deployment)).Should(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, deployment)).Should(Succeed())
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(deployment), &appsv1.Deployment{}))
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("Should report every invalid annotation and clear the report once fixed", func() {
+			Eventually(func(g Gomega) {
+				d := &appsv1.Deployment{}
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(deployment), d)).Should(Succeed())
+				g.Expect(d.Annotations).Should(HaveKey(consts.StatusValidation))
+				g.Expect(d.Annotations[consts.StatusValidation]).Should(ContainSubstring(consts.HPAMaxReplicas))
+				g.Expect(d.Annotations[consts.StatusValidation]).Should(ContainSubstring(consts.HPACpuTargetAverageUtilization))
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func() []string {
+				events := &corev1.EventList{}
+				if err := k8sClient.List(ctx, events, client.InNamespace(namespace)); err != nil {
+					return nil
+				}
+				var reasons []string
+				for _, e := range events.Items {
+					if e.InvolvedObject.Name == deploymentName {
+						reasons = append(reasons, e.Reason)
+					}
+				}
+				return reasons
+			}, timeout, interval).Should(ContainElement("InvalidAnnotations"))
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(deployment),
+				&autoscalingv2.HorizontalPodAutoscaler{}))).Should(BeTrue())
+
+			updateDeployment(k8sClient, deployment, func(d *appsv1.Deployment) {
+				d.Annotations[consts.HPAMaxReplicas] = "10"
+				d.Annotations[consts.HPACpuTargetAverageUtilization] = "80"
+			})
+
+			Eventually(func(g Gomega) {
+				d := &appsv1.Deployment{}
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(deployment), d)).Should(Succeed())
+				g.Expect(d.Annotations).ShouldNot(HaveKey(consts.StatusValidation))
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(deployment), &autoscalingv2.HorizontalPodAutoscaler{})).Should(Succeed())
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("Should write the autoscale status with the last error and clear it once healthy", func() {
+			autoscaleStatus := func(g Gomega) *kube.AutoscaleStatus {
+				d := &appsv1.Deployment{}
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(deployment), d)).Should(Succeed())
+				g.Expect(d.Annotations).Should(HaveKey(consts.StatusAutoscale))
+				status, err := kube.ParseAutoscaleStatus(d.Annotations[consts.StatusAutoscale])
+				g.Expect(err).ShouldNot(HaveOccurred())
+				g.Expect(status.ObservedAnnotationsHash).Should(Equal(kube.AnnotationsHash(d.Annotations)))
+				return status
+			}
+
+			Eventually(func(g Gomega) {
+				status := autoscaleStatus(g)
+				g.Expect(status.HPA).Should(BeEmpty())
+				g.Expect(status.LastError).Should(ContainSubstring(consts.HPAMaxReplicas))
+			}, timeout, interval).Should(Succeed())
+
+			updateDeployment(k8sClient, deployment, func(d *appsv1.Deployment) {
+				d.Annotations[consts.HPAMaxReplicas] = "10"
+				d.Annotations[consts.HPACpuTargetAverageUtilization] = "80"
+			})
+
+			var healthy *kube.AutoscaleStatus
+			Eventually(func(g Gomega) {
+				healthy = autoscaleStatus(g)
+				g.Expect(healthy.HPA).Should(Equal(kube.AutoscalerName(deploymentName, "Deployment")))
+				g.Expect(healthy.LastError).Should(BeEmpty())
+			}, timeout, interval).Should(Succeed())
+
+			By("Keeping the status unchanged while nothing changes")
+			Consistently(func(g Gomega) {
+				g.Expect(autoscaleStatus(g)).Should(Equal(healthy))
+			}, 2*time.Second, interval).Should(Succeed())
+
+			By("Removing the status once the workload is no longer autoscaled")
+			updateDeployment(k8sClient, deployment, func(d *appsv1.Deployment) {
+				delete(d.Annotations, consts.HPAMaxReplicas)
+				delete(d.Annotations, consts.HPACpuTargetAverageUtilization)
+			})
+			Eventually(func(g Gomega) {
+				d := &appsv1.Deployment{}
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(deployment), d)).Should(Succeed())
+				g.Expect(d.Annotations).ShouldNot(HaveKey(consts.StatusAutoscale))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+
+	Context("When a Deployment and a StatefulSet share the same name", func() {
+		const (
+			workloadName = "test-same-name"
+			namespace    = "test-kind-namespace"
+		)
+
+		var (
+			deployment  *appsv1.Deployment
+			statefulSet *appsv1.StatefulSet
+		)
+
+		BeforeEach(func() {
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+			Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, ns))).Should(Succeed())
+
+			replicas := int32(1)
+			labels := map[string]string{"app": workloadName}
+			template := corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "nginx", Image: "nginx:latest"}},
+				},
+			}
+			deployment = &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        workloadName,
+					Namespace:   namespace,
+					Annotations: map[string]string{consts.HPAMaxReplicas: "10"},
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Selector: &metav1.LabelSelector{MatchLabels: labels},
+					Template: template,
+				},
+			}
+			statefulSet = &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        workloadName,
+					Namespace:   namespace,
+					Annotations: map[string]string{consts.HPAMaxReplicas: "20"},
+				},
+				Spec: appsv1.StatefulSetSpec{
+					Replicas: &replicas,
+					Selector: &metav1.LabelSelector{MatchLabels: labels},
+					Template: template,
+				},
+			}
+			Expect(k8sClient.Create(ctx, deployment)).Should(Succeed())
+			Expect(k8sClient.Create(ctx, statefulSet)).Should(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, deployment)).Should(Succeed())
+			Expect(k8sClient.Delete(ctx, statefulSet)).Should(Succeed())
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(deployment), &appsv1.Deployment{})) &&
+					errors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(statefulSet), &appsv1.StatefulSet{}))
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("Should create a separate HPA for each kind", func() {
+			deploymentHPA := &autoscalingv2.HorizontalPodAutoscaler{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{
+					Name:      kube.AutoscalerName(workloadName, kube.KindDeployment),
+					Namespace: namespace,
+				}, deploymentHPA)
+			}, timeout, interval).Should(Succeed())
+			Expect(deploymentHPA.Name).Should(Equal(workloadName))
+			Expect(deploymentHPA.Spec.ScaleTargetRef.Kind).Should(Equal(kube.KindDeployment))
+			Expect(deploymentHPA.Spec.MaxReplicas).Should(Equal(int32(10)))
+
+			statefulSetHPA := &autoscalingv2.HorizontalPodAutoscaler{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{
+					Name:      kube.AutoscalerName(workloadName, kube.KindStatefulSet),
+					Namespace: namespace,
+				}, statefulSetHPA)
+			}, timeout, interval).Should(Succeed())
+			Expect(statefulSetHPA.Name).Should(Equal(workloadName + "-statefulset"))
+			Expect(statefulSetHPA.Spec.ScaleTargetRef.Kind).Should(Equal(kube.KindStatefulSet))
+			Expect(statefulSetHPA.Spec.MaxReplicas).Should(Equal(int32(20)))
+		})
+	})
+
+	Context("When reconciling a DaemonSet with HPA and VPA annotations", func() {
+		const (
+			daemonSetName = "test-daemonset"
+			namespace     = "test-daemonset-namespace"
+		)
+
+		var daemonSet *appsv1.DaemonSet
+
+		BeforeEach(func() {
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+			Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, ns))).Should(Succeed())
+
+			labels := map[string]string{"app": daemonSetName}
+			daemonSet = &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      daemonSetName,
+					Namespace: namespace,
+					Annotations: map[string]string{
+						consts.HPAMaxReplicas: "10",
+						consts.VPAUpdateMode:  "Auto",
+					},
+				},
+				Spec: appsv1.DaemonSetSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: labels},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: labels},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "agent", Image: "nginx:latest"}},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, daemonSet)).Should(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, daemonSet)).Should(Succeed())
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(daemonSet), &appsv1.DaemonSet{}))
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("Should create only the VPA and record a warning event", func() {
+			name := kube.AutoscalerName(daemonSetName, kube.KindDaemonSet)
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &vpav1.VerticalPodAutoscaler{})
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func() []string {
+				events := &corev1.EventList{}
+				if err := k8sClient.List(ctx, events, client.InNamespace(namespace)); err != nil {
+					return nil
+				}
+				var reasons []string
+				for _, e := range events.Items {
+					if e.InvolvedObject.Kind == kube.KindDaemonSet && e.InvolvedObject.Name == daemonSetName {
+						reasons = append(reasons, e.Reason)
+					}
+				}
+				return reasons
+			}, timeout, interval).Should(ContainElement("HPAUnsupported"))
+
+			Consistently(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace},
+					&autoscalingv2.HorizontalPodAutoscaler{}))
+			}, time.Second*2, interval).Should(BeTrue())
+		})
+	})
+
+	Context("When the autoscaler names of different workloads collide", func() {
+		const namespace = "test-collide-namespace"
+
+		var (
+			c           client.Client
+			recorder    *record.FakeRecorder
+			r           *AutoScaleReconciler
+			deployment  *appsv1.Deployment
+			statefulSet *appsv1.StatefulSet
+		)
+
+		BeforeEach(func() {
+			annotations := func() map[string]string {
+				return map[string]string{consts.HPAMaxReplicas: "10", consts.VPAUpdateMode: "Auto"}
+			}
+			// Deployment api-statefulset 与 StatefulSet api 的HPA/VPA都名为 api-statefulset
+			deployment = &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+				Name: "api-statefulset", Namespace: namespace, UID: "deployment-uid",
+				Finalizers: []string{consts.AutoScaleFinalizer}, Annotations: annotations(),
+			}}
+			statefulSet = &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{
+				Name: "api", Namespace: namespace, UID: "statefulset-uid",
+				Finalizers: []string{consts.AutoScaleFinalizer}, Annotations: annotations(),
+			}}
+			c = fakeClientBuilder(deployment, statefulSet).Build()
+			r, recorder = newReconciler(c)
+			r.EnableVPA = true
+		})
+
+		key := client.ObjectKey{Namespace: namespace, Name: "api-statefulset"}
+
+		It("Should keep the autoscalers with the workload which created them", func() {
+			reconcileWorkload(r, kube.KindStatefulSet, statefulSet)
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+
+			hpa := getHPA(c, key)
+			Expect(hpa.Spec.ScaleTargetRef.Kind).Should(Equal(kube.KindStatefulSet))
+			Expect(metav1.IsControlledBy(hpa, statefulSet)).Should(BeTrue())
+			vpa := &vpav1.VerticalPodAutoscaler{}
+			Expect(c.Get(ctx, key, vpa)).Should(Succeed())
+			Expect(metav1.IsControlledBy(vpa, statefulSet)).Should(BeTrue())
+
+			status := getStatus(c, deployment)
+			Expect(status.HPA).Should(BeEmpty())
+			Expect(status.VPA).Should(BeEmpty())
+			Expect(status.LastError).Should(ContainSubstring("is controlled by StatefulSet api"))
+			Expect(getStatus(c, statefulSet).LastError).Should(BeEmpty())
+			Expect(recordedEvents(recorder)).Should(ContainElement(ContainSubstring("HPAConflict")))
+
+			By("Keeping the autoscalers when the other workload stops autoscaling")
+			updateDeployment(c, deployment, func(d *appsv1.Deployment) {
+				delete(d.Annotations, consts.HPAMaxReplicas)
+				delete(d.Annotations, consts.VPAUpdateMode)
+			})
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(c.Get(ctx, key, &autoscalingv2.HorizontalPodAutoscaler{})).Should(Succeed())
+			Expect(c.Get(ctx, key, &vpav1.VerticalPodAutoscaler{})).Should(Succeed())
+		})
+
+		It("Should not adopt autoscalers controlled by another workload", func() {
+			reconcileWorkload(r, kube.KindStatefulSet, statefulSet)
+			updateDeployment(c, deployment, func(d *appsv1.Deployment) {
+				d.Annotations[consts.HPAAdopt] = "true"
+			})
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+
+			Expect(metav1.IsControlledBy(getHPA(c, key), statefulSet)).Should(BeTrue())
+			Expect(getStatus(c, deployment).LastError).Should(ContainSubstring("is controlled by StatefulSet api"))
+		})
+	})
+
+	Context("When a hand-written HPA with the same name exists", func() {
+		const (
+			deploymentName = "test-handwritten-deployment"
+			namespace      = "test-ownership-namespace"
+		)
+
+		var (
+			deployment *appsv1.Deployment
+			hpaKey     types.NamespacedName
+		)
+
+		hpaEvents := func() []string {
+			events := &corev1.EventList{}
+			if err := k8sClient.List(ctx, events, client.InNamespace(namespace)); err != nil {
+				return nil
+			}
+			var reasons []string
+			for _, e := range events.Items {
+				if e.InvolvedObject.Name == deploymentName {
+					reasons = append(reasons, e.Reason)
+				}
+			}
+			return reasons
+		}
+
+		BeforeEach(func() {
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+			Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, ns))).Should(Succeed())
+
+			hpaKey = types.NamespacedName{Name: deploymentName, Namespace: namespace}
+			hpa := &autoscalingv2.HorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Name: hpaKey.Name, Namespace: hpaKey.Namespace},
+				Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+						APIVersion: "apps/v1", Kind: kube.KindDeployment, Name: deploymentName,
+					},
+					MaxReplicas: 3,
+				},
+			}
+			Expect(k8sClient.Create(ctx, hpa)).Should(Succeed())
+
+			deployment = newDeployment(namespace, deploymentName, map[string]string{})
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, deployment)).Should(Succeed())
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(deployment), &appsv1.Deployment{}))
+			}, timeout, interval).Should(BeTrue())
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, &autoscalingv2.HorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Name: hpaKey.Name, Namespace: hpaKey.Namespace},
+			}))).Should(Succeed())
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, hpaKey, &autoscalingv2.HorizontalPodAutoscaler{}))
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("Should not delete the HPA when the deployment has no HPA annotations", func() {
+			Expect(k8sClient.Create(ctx, deployment)).Should(Succeed())
+
+			Eventually(func() []string {
+				d := &appsv1.Deployment{}
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(deployment), d); err != nil {
+					return nil
+				}
+				return d.Finalizers
+			}, timeout, interval).Should(ContainElement(consts.AutoScaleFinalizer))
+			Consistently(func() error {
+				return k8sClient.Get(ctx, hpaKey, &autoscalingv2.HorizontalPodAutoscaler{})
+			}, time.Second*2, interval).Should(Succeed())
+		})
+
+		It("Should leave the HPA untouched and record a conflict without adopt", func() {
+			deployment.Annotations[consts.HPAMaxReplicas] = "10"
+			Expect(k8sClient.Create(ctx, deployment)).Should(Succeed())
+
+			Eventually(hpaEvents, timeout, interval).Should(ContainElement("HPAConflict"))
+			hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, hpaKey, hpa)).Should(Succeed())
+			Expect(hpa.Spec.MaxReplicas).Should(Equal(int32(3)))
+			Expect(hpa.OwnerReferences).Should(BeEmpty())
+		})
+
+		It("Should adopt the HPA when adopt is set", func() {
+			deployment.Annotations[consts.HPAMaxReplicas] = "10"
+			deployment.Annotations[consts.HPAAdopt] = "true"
+			Expect(k8sClient.Create(ctx, deployment)).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+				g.Expect(k8sClient.Get(ctx, hpaKey, hpa)).Should(Succeed())
+				g.Expect(hpa.Spec.MaxReplicas).Should(Equal(int32(10)))
+				g.Expect(hpa.Labels).Should(HaveKeyWithValue(consts.ManagedByLabel, consts.ManagedByValue))
+				g.Expect(hpa.OwnerReferences).Should(HaveLen(1))
+				g.Expect(hpa.OwnerReferences[0].Name).Should(Equal(deploymentName))
+			}, timeout, interval).Should(Succeed())
+			Eventually(hpaEvents, timeout, interval).Should(ContainElement("HPAAdopted"))
+		})
+	})
+
+	Context("When applying the HPA with server-side apply", func() {
+		const (
+			deploymentName = "test-apply-deployment"
+			namespace      = "test-apply-namespace"
+		)
+
+		var (
+			deployment *appsv1.Deployment
+			stale      *autoscalingv2.HorizontalPodAutoscaler
+			applies    []client.PatchOptions
+			conflicts  bool
+			modified   bool
+			c          client.Client
+			r          *AutoScaleReconciler
+			recorder   *record.FakeRecorder
+		)
+
+		BeforeEach(func() {
+			deployment = newDeployment(namespace, deploymentName, defaultHPAAnnotations())
+			deployment.Finalizers = []string{consts.AutoScaleFinalizer}
+			stale = &autoscalingv2.HorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            deploymentName,
+					Namespace:       namespace,
+					ResourceVersion: "7",
+					Labels:          map[string]string{consts.ManagedByLabel: consts.ManagedByValue},
+				},
+				Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+						APIVersion: "apps/v1", Kind: "Deployment", Name: deploymentName,
+					},
+					MaxReplicas: 3,
+				},
+			}
+			applies = nil
+			conflicts = false
+			modified = false
+			c = fakeClientBuilder(deployment, stale).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+						if patch.Type() == types.ApplyPatchType {
+							options := client.PatchOptions{}
+							options.ApplyOptions(opts)
+							applies = append(applies, options)
+							Expect(obj.GetResourceVersion()).Should(BeEmpty())
+							if conflicts && (options.Force == nil || !*options.Force) {
+								err := errors.NewConflict(autoscalingv2.Resource("horizontalpodautoscalers"), obj.GetName(),
+									fmt.Errorf(`Apply failed with 1 conflict: conflict with "kubectl-edit": .spec.maxReplicas`))
+								err.ErrStatus.Details.Causes = []metav1.StatusCause{{
+									Type: metav1.CauseTypeFieldManagerConflict, Field: ".spec.maxReplicas",
+								}}
+								return err
+							}
+							if modified {
+								modified = false
+								other := &autoscalingv2.HorizontalPodAutoscaler{}
+								if err := c.Get(ctx, client.ObjectKeyFromObject(obj), other); err != nil {
+									return err
+								}
+								other.Spec.MaxReplicas = 5
+								if err := c.Update(ctx, other); err != nil {
+									return err
+								}
+								return errors.NewConflict(autoscalingv2.Resource("horizontalpodautoscalers"), obj.GetName(),
+									fmt.Errorf("the object has been modified; please apply your changes to the latest version and try again"))
+							}
+						}
+						return applyPatch(ctx, c, obj, patch, opts...)
+					},
+				}).
+				Build()
+			r, recorder = newReconciler(c)
+		})
+
+		It("Should apply the HPA as the infraflow-autoscale field manager without a resourceVersion", func() {
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(applies).Should(HaveLen(1))
+			Expect(applies[0].FieldManager).Should(Equal(consts.FieldManager))
+			Expect(applies[0].Force).Should(BeNil())
+
+			hpa := getHPA(c, client.ObjectKeyFromObject(stale))
+			Expect(hpa.Spec.MinReplicas).Should(HaveValue(Equal(int32(2))))
+			Expect(hpa.Spec.MaxReplicas).Should(Equal(int32(10)))
+			Expect(hpa.OwnerReferences).Should(HaveLen(1))
+
+			By("Skipping the apply when the HPA is up to date")
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(applies).Should(HaveLen(1))
+		})
+
+		It("Should return the conflict and converge on the next reconcile", func() {
+			// 第一次写入HPA前，模拟另一个写入方修改了HPA，API Server 返回并发写入的 Conflict 错误
+			modified = true
+			_, err := r.forKind(kube.KindDeployment).Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(deployment)})
+			Expect(errors.IsConflict(err)).Should(BeTrue())
+			Expect(recordedEvents(recorder)).ShouldNot(ContainElement(ContainSubstring("HPAConflict")))
+
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(applies).Should(HaveLen(2))
+			Expect(applies[1].FieldManager).Should(Equal(consts.FieldManager))
+
+			hpa := getHPA(c, client.ObjectKeyFromObject(stale))
+			Expect(hpa.Spec.MinReplicas).Should(HaveValue(Equal(int32(2))))
+			Expect(hpa.Spec.MaxReplicas).Should(Equal(int32(10)))
+			Expect(hpa.OwnerReferences).Should(HaveLen(1))
+		})
+
+		It("Should leave conflicting fields alone and report the conflict without force", func() {
+			conflicts = true
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(recordedEvents(recorder)).Should(ContainElement(HavePrefix("Warning HPAConflict HorizontalPodAutoscaler " + deploymentName)))
+			Expect(getHPA(c, client.ObjectKeyFromObject(stale)).Spec.MaxReplicas).Should(Equal(int32(3)))
+			Expect(getStatus(c, deployment).LastError).Should(ContainSubstring("--force-ownership"))
+		})
+
+		It("Should take over conflicting fields with --force-ownership", func() {
+			conflicts = true
+			r.ForceOwnership = true
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(applies).Should(HaveLen(1))
+			Expect(applies[0].Force).Should(HaveValue(BeTrue()))
+			Expect(getHPA(c, client.ObjectKeyFromObject(stale)).Spec.MaxReplicas).Should(Equal(int32(10)))
+		})
+	})
+
+	Context("When recording HPA lifecycle events", func() {
+		const (
+			deploymentName = "test-events-deployment"
+			namespace      = "test-events-namespace"
+		)
+
+		var (
+			c          client.Client
+			r          *AutoScaleReconciler
+			recorder   *record.FakeRecorder
+			deployment *appsv1.Deployment
+			failHPA    bool
+		)
+
+		BeforeEach(func() {
+			deployment = newDeployment(namespace, deploymentName, defaultHPAAnnotations())
+			deployment.Finalizers = []string{consts.AutoScaleFinalizer}
+			failHPA = false
+			c = fakeClientBuilder(deployment).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+						if _, ok := obj.(*autoscalingv2.HorizontalPodAutoscaler); ok && failHPA {
+							return errors.NewServiceUnavailable("apiserver is unavailable")
+						}
+						return applyPatch(ctx, c, obj, patch, opts...)
+					},
+				}).
+				Build()
+			r, recorder = newReconciler(c)
+		})
+
+		deleteAnnotations := func(d *appsv1.Deployment) {
+			for key := range d.Annotations {
+				delete(d.Annotations, key)
+			}
+		}
+
+		It("Should record created, updated and deleted events on the Deployment", func() {
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(recordedEvents(recorder)).Should(ConsistOf("Normal HPACreated Created HorizontalPodAutoscaler " + deploymentName))
+
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(recordedEvents(recorder)).Should(BeEmpty())
+
+			updateDeployment(c, deployment, func(d *appsv1.Deployment) {
+				d.Annotations[consts.HPAMaxReplicas] = "20"
+				d.Annotations[consts.HPAMemoryTargetAverageUtilization] = "70"
+			})
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(recordedEvents(recorder)).Should(ConsistOf("Normal HPAUpdated Updated HorizontalPodAutoscaler " + deploymentName +
+				": spec.maxReplicas: 10 -> 20, spec.metrics[Resource/memory]: added"))
+
+			updateDeployment(c, deployment, deleteAnnotations)
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(recordedEvents(recorder)).Should(ConsistOf("Normal HPADeleted Deleted HorizontalPodAutoscaler " + deploymentName))
+		})
+
+		It("Should warn about deprecated annotations once per change", func() {
+			updateDeployment(c, deployment, func(d *appsv1.Deployment) {
+				delete(d.Annotations, consts.HPAMaxReplicas)
+				d.Annotations["hpa.infraflow.co/max-replicas"] = "10"
+			})
+			deprecated := "Warning DeprecatedAnnotation Annotation hpa.infraflow.co/max-replicas is deprecated, use " +
+				consts.HPAMaxReplicas + " instead"
+
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(recordedEvents(recorder)).Should(ContainElement(deprecated))
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(recordedEvents(recorder)).ShouldNot(ContainElement(deprecated))
+
+			By("Warning again after the annotations change")
+			updateDeployment(c, deployment, func(d *appsv1.Deployment) {
+				d.Annotations["hpa.infraflow.co/max-replicas"] = "20"
+			})
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(recordedEvents(recorder)).Should(ContainElement(deprecated))
+		})
+
+		It("Should delete a managed HPA which lost its owner reference", func() {
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+
+			hpa := getHPA(c, client.ObjectKeyFromObject(deployment))
+			hpa.OwnerReferences = nil
+			Expect(c.Update(ctx, hpa)).Should(Succeed())
+			other := &autoscalingv2.HorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "other-deployment",
+					Namespace: namespace,
+					Labels:    map[string]string{consts.ManagedByLabel: consts.ManagedByValue},
+				},
+				Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+						APIVersion: "apps/v1", Kind: kube.KindDeployment, Name: "other-deployment",
+					},
+					MaxReplicas: 3,
+				},
+			}
+			Expect(c.Create(ctx, other)).Should(Succeed())
+			recordedEvents(recorder)
+
+			updateDeployment(c, deployment, deleteAnnotations)
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(recordedEvents(recorder)).Should(ConsistOf("Normal HPADeleted Deleted HorizontalPodAutoscaler " + deploymentName))
+			Expect(errors.IsNotFound(c.Get(ctx, client.ObjectKeyFromObject(hpa), hpa))).Should(BeTrue())
+
+			By("Keeping the managed HPA of another workload")
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(other), other)).Should(Succeed())
+		})
+
+		It("Should also record the events on the HPA when enabled", func() {
+			r.AutoscalerEvents = true
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			// 事件分别记录在 Deployment 和 HPA 上
+			Expect(recordedEvents(recorder)).Should(Equal([]string{
+				"Normal HPACreated Created HorizontalPodAutoscaler " + deploymentName,
+				"Normal HPACreated Created HorizontalPodAutoscaler " + deploymentName,
+			}))
+		})
+
+		It("Should record validation failures and API errors", func() {
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			recordedEvents(recorder)
+
+			updateDeployment(c, deployment, func(d *appsv1.Deployment) {
+				d.Annotations[consts.HPAMaxReplicas] = "1"
+			})
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(recordedEvents(recorder)).Should(ConsistOf(HavePrefix("Warning InvalidAnnotations invalid annotations: " +
+				"metadata.annotations[" + consts.HPAMaxReplicas + "]")))
+
+			updateDeployment(c, deployment, func(d *appsv1.Deployment) {
+				d.Annotations[consts.HPAMaxReplicas] = "20"
+			})
+			failHPA = true
+			_, err := r.forKind(kube.KindDeployment).Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(deployment)})
+			Expect(errors.IsServiceUnavailable(err)).Should(BeTrue())
+			Expect(recordedEvents(recorder)).Should(ConsistOf("Warning ReconcileFailed failed to reconcile HPA: apiserver is unavailable"))
+		})
+	})
+
+	Context("When naming the HPA with a name template", func() {
+		const (
+			deploymentName = "test-naming-deployment"
+			namespace      = "test-naming-namespace"
+		)
+
+		var (
+			c          client.Client
+			r          *AutoScaleReconciler
+			deployment *appsv1.Deployment
+		)
+
+		BeforeEach(func() {
+			deployment = newDeployment(namespace, deploymentName, defaultHPAAnnotations())
+			deployment.Finalizers = []string{consts.AutoScaleFinalizer}
+			c = fakeClientBuilder(deployment).Build()
+			nameTemplate, err := kube.ParseNameTemplate("{{.Name}}-{{.Kind | lower}}-hpa")
+			Expect(err).ShouldNot(HaveOccurred())
+			r, _ = newReconciler(c)
+			r.HPANameTemplate = nameTemplate
+		})
+
+		hpaNames := func() []string {
+			hpas := &autoscalingv2.HorizontalPodAutoscalerList{}
+			Expect(c.List(ctx, hpas, client.InNamespace(namespace))).Should(Succeed())
+			var names []string
+			for _, hpa := range hpas.Items {
+				names = append(names, hpa.Name)
+			}
+			return names
+		}
+
+		It("Should name the HPA with the template and set the standard labels", func() {
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(hpaNames()).Should(ConsistOf(deploymentName + "-deployment-hpa"))
+
+			hpa := getHPA(c, types.NamespacedName{Name: deploymentName + "-deployment-hpa", Namespace: namespace})
+			Expect(hpa.Labels).Should(Equal(map[string]string{
+				consts.ManagedByLabel:    consts.ManagedByValue,
+				consts.WorkloadKindLabel: kube.KindDeployment,
+				consts.WorkloadNameLabel: deploymentName,
+			}))
+			Expect(hpa.Annotations).Should(HaveKey(consts.SourceHashAnnotation))
+			Expect(getStatus(c, deployment).HPA).Should(Equal(deploymentName + "-deployment-hpa"))
+		})
+
+		It("Should delete the HPA with the old name after a rename", func() {
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			updateDeployment(c, deployment, func(d *appsv1.Deployment) {
+				d.Annotations[consts.HPANameTemplate] = "{{.Name}}-autoscaler"
+			})
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(hpaNames()).Should(ConsistOf(deploymentName + "-autoscaler"))
+		})
+
+		It("Should report an invalid name template annotation", func() {
+			updateDeployment(c, deployment, func(d *appsv1.Deployment) {
+				d.Annotations[consts.HPANameTemplate] = "{{.Kind}}_{{.Name}}"
+			})
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(hpaNames()).Should(BeEmpty())
+			Expect(getStatus(c, deployment).LastError).Should(ContainSubstring(consts.HPANameTemplate))
+		})
+	})
+
+	Context("When pausing the HPA", func() {
+		const (
+			deploymentName = "test-pause-deployment"
+			namespace      = "test-pause-namespace"
+		)
+
+		var (
+			c          client.Client
+			r          *AutoScaleReconciler
+			recorder   *record.FakeRecorder
+			deployment *appsv1.Deployment
+		)
+
+		BeforeEach(func() {
+			replicas := int32(4)
+			deployment = newDeployment(namespace, deploymentName, defaultHPAAnnotations())
+			deployment.Finalizers = []string{consts.AutoScaleFinalizer}
+			deployment.Spec.Replicas = &replicas
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+			c = fakeClientBuilder(deployment, ns).Build()
+			r, recorder = newReconciler(c)
+		})
+
+		hpaKey := types.NamespacedName{Name: deploymentName, Namespace: namespace}
+
+		It("Should pin the HPA to the current replicas and restore it when resumed", func() {
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			recordedEvents(recorder)
+
+			updateDeployment(c, deployment, func(d *appsv1.Deployment) {
+				d.Annotations[consts.HPAPaused] = "true"
+			})
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			hpa := getHPA(c, hpaKey)
+			Expect(hpa.Spec.MinReplicas).Should(HaveValue(Equal(int32(4))))
+			Expect(hpa.Spec.MaxReplicas).Should(Equal(int32(4)))
+			Expect(hpa.Spec.Metrics).Should(HaveLen(1))
+			Expect(getStatus(c, deployment).Paused).Should(Equal(kube.PausedByAnnotation))
+			Expect(recordedEvents(recorder)).Should(ConsistOf("Normal HPAPaused Paused HorizontalPodAutoscaler " + deploymentName +
+				" at 4 replicas (paused by annotation)"))
+
+			By("Keeping the pinned replicas when the workload is scaled while paused")
+			updateDeployment(c, deployment, func(d *appsv1.Deployment) {
+				replicas := int32(7)
+				d.Spec.Replicas = &replicas
+			})
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(getHPA(c, hpaKey).Spec.MaxReplicas).Should(Equal(int32(4)))
+			Expect(recordedEvents(recorder)).Should(BeEmpty())
+
+			updateDeployment(c, deployment, func(d *appsv1.Deployment) {
+				delete(d.Annotations, consts.HPAPaused)
+			})
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			hpa = getHPA(c, hpaKey)
+			Expect(hpa.Spec.MinReplicas).Should(HaveValue(Equal(int32(2))))
+			Expect(hpa.Spec.MaxReplicas).Should(Equal(int32(10)))
+			Expect(hpa.Annotations).ShouldNot(HaveKey(consts.PausedReplicasAnnotation))
+			Expect(getStatus(c, deployment).Paused).Should(BeEmpty())
+			Expect(recordedEvents(recorder)).Should(ConsistOf("Normal HPAResumed Resumed HorizontalPodAutoscaler " + deploymentName +
+				": spec.minReplicas: 4 -> 2, spec.maxReplicas: 4 -> 10"))
+		})
+
+		It("Should resume the HPA when the configured replicas equal the pinned replicas", func() {
+			updateDeployment(c, deployment, func(d *appsv1.Deployment) {
+				d.Annotations[consts.HPAMinReplicas] = "4"
+				d.Annotations[consts.HPAMaxReplicas] = "4"
+				d.Annotations[consts.HPAPaused] = "true"
+			})
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(getHPA(c, hpaKey).Annotations).Should(HaveKeyWithValue(consts.PausedReplicasAnnotation, "4"))
+			recordedEvents(recorder)
+
+			updateDeployment(c, deployment, func(d *appsv1.Deployment) {
+				delete(d.Annotations, consts.HPAPaused)
+			})
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			hpa := getHPA(c, hpaKey)
+			Expect(hpa.Spec.MinReplicas).Should(HaveValue(Equal(int32(4))))
+			Expect(hpa.Spec.MaxReplicas).Should(Equal(int32(4)))
+			Expect(hpa.Annotations).ShouldNot(HaveKey(consts.PausedReplicasAnnotation))
+			Expect(getStatus(c, deployment).Paused).Should(BeEmpty())
+			Expect(recordedEvents(recorder)).Should(ConsistOf("Normal HPAResumed Resumed HorizontalPodAutoscaler " + deploymentName))
+
+			By("Pinning the current replicas instead of the stale ones when paused again")
+			updateDeployment(c, deployment, func(d *appsv1.Deployment) {
+				replicas := int32(6)
+				d.Spec.Replicas = &replicas
+				d.Annotations[consts.HPAMinReplicas] = "2"
+				d.Annotations[consts.HPAMaxReplicas] = "10"
+				d.Annotations[consts.HPAPaused] = "true"
+			})
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(getHPA(c, hpaKey).Spec.MaxReplicas).Should(Equal(int32(6)))
+		})
+
+		It("Should pause the HPAs of a namespace with the paused label", func() {
+			ns := &corev1.Namespace{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: namespace}, ns)).Should(Succeed())
+			ns.Labels = map[string]string{consts.PausedLabel: "true"}
+			Expect(c.Update(ctx, ns)).Should(Succeed())
+
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(getHPA(c, hpaKey).Spec.MaxReplicas).Should(Equal(int32(4)))
+			Expect(getStatus(c, deployment).Paused).Should(Equal(kube.PausedByNamespace))
+			Expect(recordedEvents(recorder)).Should(ConsistOf(
+				"Normal HPACreated Created HorizontalPodAutoscaler "+deploymentName,
+				"Normal HPAPaused Paused HorizontalPodAutoscaler "+deploymentName+" at 4 replicas (paused by namespace)",
+			))
+		})
+
+		It("Should pause all HPAs with --global-pause", func() {
+			r.GlobalPause = true
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(getHPA(c, hpaKey).Spec.MaxReplicas).Should(Equal(int32(4)))
+			Expect(getStatus(c, deployment).Paused).Should(Equal(kube.PausedGlobally))
+
+			r.GlobalPause = false
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(getHPA(c, hpaKey).Spec.MaxReplicas).Should(Equal(int32(10)))
+		})
+
+		It("Should leave the HPA unchanged when the paused annotation is invalid", func() {
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+
+			updateDeployment(c, deployment, func(d *appsv1.Deployment) {
+				d.Annotations[consts.HPAPaused] = "yes"
+				d.Annotations[consts.HPAMaxReplicas] = "20"
+			})
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(getHPA(c, hpaKey).Spec.MaxReplicas).Should(Equal(int32(10)))
+			Expect(getStatus(c, deployment).LastError).Should(ContainSubstring(consts.HPAPaused))
+		})
+	})
+
+	Context("When scaling on a schedule", func() {
+		const (
+			deploymentName = "test-schedule-deployment"
+			namespace      = "test-schedule-namespace"
+		)
+
+		var (
+			c          client.Client
+			clk        *clocktesting.FakePassiveClock
+			r          *AutoScaleReconciler
+			deployment *appsv1.Deployment
+			start      time.Time
+		)
+
+		BeforeEach(func() {
+			shanghai, err := time.LoadLocation("Asia/Shanghai")
+			Expect(err).ShouldNot(HaveOccurred())
+			// 2025-06-02 是周一
+			start = time.Date(2025, 6, 2, 7, 30, 0, 0, shanghai)
+			deployment = newDeployment(namespace, deploymentName, map[string]string{
+				consts.HPAMinReplicas:                 "3",
+				consts.HPAMaxReplicas:                 "10",
+				consts.HPACpuTargetAverageUtilization: "80",
+				consts.ScheduleCron:                   "0 8 * * 1-5",
+				consts.ScheduleTimeZone:               "Asia/Shanghai",
+				consts.ScheduleDuration:               "10h",
+				consts.ScheduleMinReplicas:            "20",
+			})
+			deployment.Finalizers = []string{consts.AutoScaleFinalizer}
+			c = fakeClientBuilder(deployment).Build()
+			clk = clocktesting.NewFakePassiveClock(start)
+			r, _ = newReconciler(c)
+			r.Clock = clk
+		})
+
+		hpaKey := types.NamespacedName{Name: deploymentName, Namespace: namespace}
+
+		It("Should override the replicas during the window and requeue at its boundaries", func() {
+			By("Using the annotations before the window starts")
+			result := reconcileWorkload(r, kube.KindDeployment, deployment)
+			hpa := getHPA(c, hpaKey)
+			Expect(hpa.Spec.MinReplicas).Should(HaveValue(Equal(int32(3))))
+			Expect(hpa.Spec.MaxReplicas).Should(Equal(int32(10)))
+			Expect(result.RequeueAfter).Should(Equal(30 * time.Minute))
+			status := getStatus(c, deployment)
+			Expect(status.Schedule).Should(BeEmpty())
+			Expect(status.NextScheduleTime).ShouldNot(BeNil())
+			Expect(status.NextScheduleTime.Time).Should(BeTemporally("==", start.Add(30*time.Minute)))
+
+			By("Overriding minReplicas and raising maxReplicas inside the window")
+			clk.SetTime(start.Add(30 * time.Minute))
+			result = reconcileWorkload(r, kube.KindDeployment, deployment)
+			hpa = getHPA(c, hpaKey)
+			Expect(hpa.Spec.MinReplicas).Should(HaveValue(Equal(int32(20))))
+			Expect(hpa.Spec.MaxReplicas).Should(Equal(int32(20)))
+			Expect(result.RequeueAfter).Should(Equal(10 * time.Hour))
+			status = getStatus(c, deployment)
+			Expect(status.Schedule).Should(Equal("0 8 * * 1-5"))
+			Expect(status.NextScheduleTime.Time).Should(BeTemporally("==", start.Add(10*time.Hour+30*time.Minute)))
+
+			By("Restoring the annotations after the window ends")
+			clk.SetTime(start.Add(10*time.Hour + 30*time.Minute))
+			result = reconcileWorkload(r, kube.KindDeployment, deployment)
+			hpa = getHPA(c, hpaKey)
+			Expect(hpa.Spec.MinReplicas).Should(HaveValue(Equal(int32(3))))
+			Expect(hpa.Spec.MaxReplicas).Should(Equal(int32(10)))
+			Expect(result.RequeueAfter).Should(Equal(14 * time.Hour))
+			Expect(getStatus(c, deployment).Schedule).Should(BeEmpty())
+		})
+
+		It("Should requeue at the resync period when it is earlier than the next boundary", func() {
+			r.ResyncPeriod = 10 * time.Minute
+			Expect(reconcileWorkload(r, kube.KindDeployment, deployment).RequeueAfter).Should(Equal(10 * time.Minute))
+		})
+
+		It("Should leave the HPA unchanged when the schedule annotations are invalid", func() {
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+
+			updateDeployment(c, deployment, func(d *appsv1.Deployment) {
+				d.Annotations[consts.ScheduleCron] = "0 8 * *"
+				d.Annotations[consts.HPAMaxReplicas] = "30"
+			})
+
+			clk.SetTime(start.Add(time.Hour))
+			result := reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(getHPA(c, hpaKey).Spec.MaxReplicas).Should(Equal(int32(10)))
+			Expect(result.RequeueAfter).Should(BeZero())
+			status := getStatus(c, deployment)
+			Expect(status.LastError).Should(ContainSubstring(consts.ScheduleCron))
+			Expect(status.NextScheduleTime).Should(BeNil())
+		})
+	})
+
+	Context("When scaling with a holiday calendar", func() {
+		const (
+			deploymentName = "test-calendar-deployment"
+			namespace      = "test-calendar-namespace"
+			calendarName   = "retail-holidays"
+		)
+
+		var (
+			c          client.Client
+			clk        *clocktesting.FakePassiveClock
+			r          *AutoScaleReconciler
+			deployment *appsv1.Deployment
+			start      time.Time
+		)
+
+		newCalendar := func() *autoscalev1alpha1.ClusterAutoscaleCalendar {
+			min, max := int32(50), int32(60)
+			return &autoscalev1alpha1.ClusterAutoscaleCalendar{
+				ObjectMeta: metav1.ObjectMeta{Name: calendarName},
+				Spec: autoscalev1alpha1.ClusterAutoscaleCalendarSpec{
+					TimeZone: "Asia/Shanghai",
+					Entries: []autoscalev1alpha1.CalendarEntry{
+						{Name: "black-friday", Start: "2025-11-28", End: "2025-11-28", MinReplicas: &min, MaxReplicas: &max},
+					},
+				},
+			}
+		}
+		// build 使用 annotations 创建 Deployment，并与objs一起放入 fake client
+		build := func(annotations map[string]string, objs ...client.Object) {
+			deployment = newDeployment(namespace, deploymentName, annotations)
+			deployment.Finalizers = []string{consts.AutoScaleFinalizer}
+			c = fakeClientBuilder(append(objs, deployment)...).Build()
+			r, _ = newReconciler(c)
+			r.Clock = clk
+		}
+
+		BeforeEach(func() {
+			shanghai, err := time.LoadLocation("Asia/Shanghai")
+			Expect(err).ShouldNot(HaveOccurred())
+			// 2025-11-28 是周五
+			start = time.Date(2025, 11, 28, 9, 0, 0, 0, shanghai)
+			clk = clocktesting.NewFakePassiveClock(start)
+		})
+
+		hpaKey := types.NamespacedName{Name: deploymentName, Namespace: namespace}
+		// activeEntries 返回 infraflow_autoscale_calendar_entry_active 中该工作负载值为1的 <calendar>/<entry>
+		activeEntries := func() []string {
+			families, err := ctrlmetrics.Registry.Gather()
+			Expect(err).ShouldNot(HaveOccurred())
+			var entries []string
+			for _, family := range families {
+				if family.GetName() != "infraflow_autoscale_calendar_entry_active" {
+					continue
+				}
+				for _, m := range family.GetMetric() {
+					labels := map[string]string{}
+					for _, label := range m.GetLabel() {
+						labels[label.GetName()] = label.GetValue()
+					}
+					if labels["namespace"] == namespace && labels["name"] == deploymentName && m.GetGauge().GetValue() == 1 {
+						entries = append(entries, labels["calendar"]+"/"+labels["entry"])
+					}
+				}
+			}
+			return entries
+		}
+
+		It("Should raise the schedule to the floors of the active entry", func() {
+			build(map[string]string{
+				consts.HPAMinReplicas:                 "3",
+				consts.HPAMaxReplicas:                 "10",
+				consts.HPACpuTargetAverageUtilization: "80",
+				consts.HPACalendar:                    calendarName,
+				consts.ScheduleCron:                   "0 8 * * 1-5",
+				consts.ScheduleTimeZone:               "Asia/Shanghai",
+				consts.ScheduleDuration:               "10h",
+				consts.ScheduleMinReplicas:            "20",
+			}, newCalendar())
+
+			By("Raising the replicas of the schedule to the floors of the entry")
+			result := reconcileWorkload(r, kube.KindDeployment, deployment)
+			hpa := getHPA(c, hpaKey)
+			Expect(hpa.Spec.MinReplicas).Should(HaveValue(Equal(int32(50))))
+			Expect(hpa.Spec.MaxReplicas).Should(Equal(int32(60)))
+			status := getStatus(c, deployment)
+			Expect(status.Calendar).Should(Equal(calendarName))
+			Expect(status.CalendarEntry).Should(Equal("black-friday"))
+			Expect(status.Schedule).Should(Equal("0 8 * * 1-5"))
+			// 定时窗口在 18:00 结束，早于日历条目的结束时间
+			Expect(result.RequeueAfter).Should(Equal(9 * time.Hour))
+			Expect(activeEntries()).Should(ConsistOf(calendarName + "/black-friday"))
+
+			By("Restoring the annotations after the entry ends")
+			clk.SetTime(start.Add(15 * time.Hour))
+			result = reconcileWorkload(r, kube.KindDeployment, deployment)
+			hpa = getHPA(c, hpaKey)
+			Expect(hpa.Spec.MinReplicas).Should(HaveValue(Equal(int32(3))))
+			Expect(hpa.Spec.MaxReplicas).Should(Equal(int32(10)))
+			status = getStatus(c, deployment)
+			Expect(status.Calendar).Should(Equal(calendarName))
+			Expect(status.CalendarEntry).Should(BeEmpty())
+			// 下一个定时窗口在周一 08:00 开始
+			Expect(result.RequeueAfter).Should(Equal(56 * time.Hour))
+			Expect(activeEntries()).Should(BeEmpty())
+		})
+
+		It("Should keep the schedule above the floors of the entry", func() {
+			min, max := int32(10), int32(40)
+			calendar := newCalendar()
+			calendar.Spec.Entries[0].MinReplicas = &min
+			calendar.Spec.Entries[0].MaxReplicas = &max
+			build(map[string]string{
+				consts.HPAMinReplicas:                 "3",
+				consts.HPAMaxReplicas:                 "10",
+				consts.HPACpuTargetAverageUtilization: "80",
+				consts.HPACalendar:                    calendarName,
+				consts.ScheduleCron:                   "0 8 * * 1-5",
+				consts.ScheduleTimeZone:               "Asia/Shanghai",
+				consts.ScheduleDuration:               "10h",
+				consts.ScheduleMinReplicas:            "20",
+			}, calendar)
+
+			By("Keeping minReplicas of the schedule above the floor of the entry")
+			result := reconcileWorkload(r, kube.KindDeployment, deployment)
+			hpa := getHPA(c, hpaKey)
+			Expect(hpa.Spec.MinReplicas).Should(HaveValue(Equal(int32(20))))
+			Expect(hpa.Spec.MaxReplicas).Should(Equal(int32(40)))
+			status := getStatus(c, deployment)
+			Expect(status.CalendarEntry).Should(Equal("black-friday"))
+			Expect(status.Schedule).Should(Equal("0 8 * * 1-5"))
+			Expect(result.RequeueAfter).Should(Equal(9 * time.Hour))
+
+			By("Falling back to the floors of the entry after the schedule ends")
+			clk.SetTime(start.Add(9 * time.Hour))
+			result = reconcileWorkload(r, kube.KindDeployment, deployment)
+			hpa = getHPA(c, hpaKey)
+			Expect(hpa.Spec.MinReplicas).Should(HaveValue(Equal(int32(10))))
+			Expect(hpa.Spec.MaxReplicas).Should(Equal(int32(40)))
+			status = getStatus(c, deployment)
+			Expect(status.CalendarEntry).Should(Equal("black-friday"))
+			Expect(status.Schedule).Should(BeEmpty())
+			// 日历条目在当天 24:00 结束
+			Expect(result.RequeueAfter).Should(Equal(6 * time.Hour))
+		})
+
+		It("Should use the calendar of the AutoscalePolicy", func() {
+			min := int32(2)
+			build(nil, newCalendar(), &autoscalev1alpha1.AutoscalePolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-calendar-policy", Namespace: namespace},
+				Spec: autoscalev1alpha1.AutoscalePolicySpec{
+					TargetRef: &autoscalev1alpha1.PolicyTargetReference{Kind: kube.KindDeployment, Name: deploymentName},
+					HPA: &autoscalev1alpha1.HPASpec{
+						MinReplicas: &min,
+						MaxReplicas: 8,
+						Metrics:     []autoscalingv2.MetricSpec{kube.CPUUtilizationMetric(70)},
+						Calendar:    calendarName,
+					},
+				},
+			})
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(getHPA(c, hpaKey).Spec.MinReplicas).Should(HaveValue(Equal(int32(50))))
+			Expect(getStatus(c, deployment).CalendarEntry).Should(Equal("black-friday"))
+
+			By("Removing the metric once the workload is deleted")
+			Expect(c.Delete(ctx, deployment)).Should(Succeed())
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			Expect(activeEntries()).Should(BeEmpty())
+		})
+
+		It("Should report a calendar which does not exist", func() {
+			build(map[string]string{
+				consts.HPAMaxReplicas: "10",
+				consts.HPACalendar:    "missing",
+			})
+			reconcileWorkload(r, kube.KindDeployment, deployment)
+			err := c.Get(ctx, hpaKey, &autoscalingv2.HorizontalPodAutoscaler{})
+			Expect(errors.IsNotFound(err)).Should(BeTrue())
+			Expect(getStatus(c, deployment).LastError).Should(ContainSubstring(consts.HPACalendar))
+			Expect(activeEntries()).Should(BeEmpty())
+		})
+
+		It("Should reconcile every workload referencing a changed calendar", func() {
+			calendar := newCalendar()
+			c = fakeClientBuilder(
+				calendar,
+				newDeployment("ns-a", "by-annotation", map[string]string{consts.HPACalendar: calendarName}),
+				newDeployment("ns-a", "other-calendar", map[string]string{consts.HPACalendar: "other"}),
+				newDeployment("ns-b", "by-policy", nil),
+				newDeployment("ns-c", "by-profile", map[string]string{consts.HPAProfile: "web-holidays"}),
+				&autoscalev1alpha1.AutoscalePolicy{
+					ObjectMeta: metav1.ObjectMeta{Name: "holidays", Namespace: "ns-b"},
+					Spec:       autoscalev1alpha1.AutoscalePolicySpec{HPA: &autoscalev1alpha1.HPASpec{MaxReplicas: 10, Calendar: calendarName}},
+				},
+				&autoscalev1alpha1.ClusterAutoscaleProfile{
+					ObjectMeta: metav1.ObjectMeta{Name: "web-holidays"},
+					Spec:       autoscalev1alpha1.ClusterAutoscaleProfileSpec{HPA: autoscalev1alpha1.HPASpec{MaxReplicas: 10, Calendar: calendarName}},
+				},
+			).
+				WithIndex(&appsv1.Deployment{}, profileIndexField, annotationIndexer(consts.HPAProfile)).
+				WithIndex(&appsv1.Deployment{}, calendarIndexField, annotationIndexer(consts.HPACalendar)).
+				Build()
+			r, _ = newReconciler(c)
+
+			var names []string
+			for _, request := range r.workloadsReferencingCalendar(kube.KindDeployment)(ctx, calendar) {
+				names = append(names, request.String())
+			}
+			Expect(names).Should(ConsistOf("ns-a/by-annotation", "ns-b/by-policy", "ns-c/by-profile"))
+		})
+	})
+
+	Context("When workloads are selected by an AutoscalePolicy", func() {
+		const namespace = "test-policy-namespace"
+
+		var deployment *appsv1.Deployment
+
+		newPolicy := func(name string, spec autoscalev1alpha1.AutoscalePolicySpec) *autoscalev1alpha1.AutoscalePolicy {
+			return &autoscalev1alpha1.AutoscalePolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				Spec:       spec,
+			}
+		}
+		hpaSpec := func(min, max int32) *autoscalev1alpha1.HPASpec {
+			return &autoscalev1alpha1.HPASpec{
+				MinReplicas: &min,
+				MaxReplicas: max,
+				Metrics:     []autoscalingv2.MetricSpec{kube.CPUUtilizationMetric(70)},
+			}
+		}
+		maxReplicas := func() int32 {
+			hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(deployment), hpa); err != nil {
+				return 0
+			}
+			return hpa.Spec.MaxReplicas
+		}
+		hpaDeleted := func() bool {
+			return errors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(deployment), &autoscalingv2.HorizontalPodAutoscaler{}))
+		}
+		cleanupPolicies := func() {
+			Expect(k8sClient.DeleteAllOf(ctx, &autoscalev1alpha1.AutoscalePolicy{}, client.InNamespace(namespace))).Should(Succeed())
+		}
+
+		BeforeEach(func() {
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+			Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, ns))).Should(Succeed())
+
+			deployment = newDeployment(namespace, "test-policy", nil)
+			deployment.Labels = map[string]string{"tier": "web"}
+			Expect(k8sClient.Create(ctx, deployment)).Should(Succeed())
+		})
+
+		AfterEach(func() {
+			cleanupPolicies()
+			Expect(k8sClient.Delete(ctx, deployment)).Should(Succeed())
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(deployment), &appsv1.Deployment{}))
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("Should build the HPA from the typed spec of a selector policy", func() {
+			Expect(k8sClient.Create(ctx, newPolicy("web", autoscalev1alpha1.AutoscalePolicySpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "web"}},
+				HPA:      hpaSpec(2, 8),
+			}))).Should(Succeed())
+
+			Eventually(maxReplicas, timeout, interval).Should(Equal(int32(8)))
+			hpa := getHPA(k8sClient, client.ObjectKeyFromObject(deployment))
+			Expect(*hpa.Spec.MinReplicas).Should(Equal(int32(2)))
+			Expect(hpa.Spec.Metrics).Should(HaveLen(1))
+			Expect(*hpa.Spec.Metrics[0].Resource.Target.AverageUtilization).Should(Equal(int32(70)))
+		})
+
+		It("Should update and delete the HPA with the policy", func() {
+			policy := newPolicy("web", autoscalev1alpha1.AutoscalePolicySpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "web"}},
+				HPA:      hpaSpec(2, 8),
+			})
+			Expect(k8sClient.Create(ctx, policy)).Should(Succeed())
+			Eventually(maxReplicas, timeout, interval).Should(Equal(int32(8)))
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(policy), policy)).Should(Succeed())
+			policy.Spec.HPA.MaxReplicas = 12
+			Expect(k8sClient.Update(ctx, policy)).Should(Succeed())
+			Eventually(maxReplicas, timeout, interval).Should(Equal(int32(12)))
+
+			Expect(k8sClient.Delete(ctx, policy)).Should(Succeed())
+			Eventually(hpaDeleted, timeout, interval).Should(BeTrue())
+		})
+
+		It("Should stop managing the workload when its labels no longer match", func() {
+			Expect(k8sClient.Create(ctx, newPolicy("web", autoscalev1alpha1.AutoscalePolicySpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "web"}},
+				HPA:      hpaSpec(2, 8),
+			}))).Should(Succeed())
+			Eventually(maxReplicas, timeout, interval).Should(Equal(int32(8)))
+
+			updateDeployment(k8sClient, deployment, func(d *appsv1.Deployment) {
+				d.Labels["tier"] = "batch"
+			})
+			Eventually(hpaDeleted, timeout, interval).Should(BeTrue())
+		})
+
+		It("Should prefer a targetRef policy over a selector policy", func() {
+			Expect(k8sClient.Create(ctx, newPolicy("web", autoscalev1alpha1.AutoscalePolicySpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "web"}},
+				HPA:      hpaSpec(2, 8),
+			}))).Should(Succeed())
+			Expect(k8sClient.Create(ctx, newPolicy("test-policy", autoscalev1alpha1.AutoscalePolicySpec{
+				TargetRef: &autoscalev1alpha1.PolicyTargetReference{Kind: kube.KindDeployment, Name: deployment.Name},
+				HPA:       hpaSpec(3, 15),
+			}))).Should(Succeed())
+
+			Eventually(maxReplicas, timeout, interval).Should(Equal(int32(15)))
+			Consistently(maxReplicas, time.Second, interval).Should(Equal(int32(15)))
+		})
+
+		It("Should prefer the HPA annotations of the workload over the policy", func() {
+			updateDeployment(k8sClient, deployment, func(d *appsv1.Deployment) {
+				d.Annotations = map[string]string{consts.HPAMaxReplicas: "5"}
+			})
+			Eventually(maxReplicas, timeout, interval).Should(Equal(int32(5)))
+
+			Expect(k8sClient.Create(ctx, newPolicy("web", autoscalev1alpha1.AutoscalePolicySpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "web"}},
+				HPA:      hpaSpec(2, 8),
+			}))).Should(Succeed())
+			Consistently(maxReplicas, 2*time.Second, interval).Should(Equal(int32(5)))
+		})
+
+		It("Should reject policies with minReplicas greater than maxReplicas", func() {
+			err := k8sClient.Create(ctx, newPolicy("invalid", autoscalev1alpha1.AutoscalePolicySpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "web"}},
+				HPA:      hpaSpec(10, 2),
+			}))
+			Expect(errors.IsInvalid(err)).Should(BeTrue())
+		})
+
+		It("Should reject policies with both targetRef and selector", func() {
+			err := k8sClient.Create(ctx, newPolicy("invalid", autoscalev1alpha1.AutoscalePolicySpec{
+				TargetRef: &autoscalev1alpha1.PolicyTargetReference{Kind: kube.KindDeployment, Name: deployment.Name},
+				Selector:  &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "web"}},
+				HPA:       hpaSpec(2, 8),
+			}))
+			Expect(errors.IsInvalid(err)).Should(BeTrue())
+		})
+	})
+
+	Context("When workloads reference a ClusterAutoscaleProfile", func() {
+		const namespace = "test-profile-namespace"
+
+		var (
+			deployment *appsv1.Deployment
+			profile    *autoscalev1alpha1.ClusterAutoscaleProfile
+		)
+
+		minReplicas := func() int32 {
+			hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(deployment), hpa); err != nil || hpa.Spec.MinReplicas == nil {
+				return 0
+			}
+			return *hpa.Spec.MinReplicas
+		}
+		createDeployment := func(annotations map[string]string) {
+			deployment = newDeployment(namespace, "test-profile", annotations)
+			Expect(k8sClient.Create(ctx, deployment)).Should(Succeed())
+		}
+
+		BeforeEach(func() {
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+			Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, ns))).Should(Succeed())
+
+			min := int32(2)
+			window := int32(300)
+			profile = &autoscalev1alpha1.ClusterAutoscaleProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "web-standard"},
+				Spec: autoscalev1alpha1.ClusterAutoscaleProfileSpec{
+					HPA: autoscalev1alpha1.HPASpec{
+						MinReplicas: &min,
+						MaxReplicas: 10,
+						Metrics: []autoscalingv2.MetricSpec{
+							kube.CPUUtilizationMetric(70),
+							kube.MemoryUtilizationMetric(80),
+						},
+						Behavior: &autoscalingv2.HorizontalPodAutoscalerBehavior{
+							ScaleDown: &autoscalingv2.HPAScalingRules{StabilizationWindowSeconds: &window},
+						},
+					},
+				},
+			}
+		})
+
+		AfterEach(func() {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, profile))).Should(Succeed())
+			Expect(k8sClient.Delete(ctx, deployment)).Should(Succeed())
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(deployment), &appsv1.Deployment{}))
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("Should build the HPA from the profile with annotations overriding individual fields", func() {
+			Expect(k8sClient.Create(ctx, profile)).Should(Succeed())
+			createDeployment(map[string]string{
+				consts.HPAProfile:                     profile.Name,
+				consts.HPAMaxReplicas:                 "20",
+				consts.HPACpuTargetAverageUtilization: "50",
+			})
+
+			Eventually(minReplicas, timeout, interval).Should(Equal(int32(2)))
+			hpa := getHPA(k8sClient, client.ObjectKeyFromObject(deployment))
+			Expect(hpa.Spec.MaxReplicas).Should(Equal(int32(20)))
+			Expect(hpa.Spec.Metrics).Should(HaveLen(2))
+			Expect(hpa.Spec.Metrics[0].Resource.Name).Should(Equal(corev1.ResourceCPU))
+			Expect(*hpa.Spec.Metrics[0].Resource.Target.AverageUtilization).Should(Equal(int32(50)))
+			Expect(hpa.Spec.Metrics[1].Resource.Name).Should(Equal(corev1.ResourceMemory))
+			Expect(*hpa.Spec.Behavior.ScaleDown.StabilizationWindowSeconds).Should(Equal(int32(300)))
+		})
+
+		It("Should update the HPA when the profile changes", func() {
+			Expect(k8sClient.Create(ctx, profile)).Should(Succeed())
+			createDeployment(map[string]string{consts.HPAProfile: profile.Name})
+			Eventually(minReplicas, timeout, interval).Should(Equal(int32(2)))
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(profile), profile)).Should(Succeed())
+			min := int32(4)
+			profile.Spec.HPA.MinReplicas = &min
+			Expect(k8sClient.Update(ctx, profile)).Should(Succeed())
+			Eventually(minReplicas, timeout, interval).Should(Equal(int32(4)))
+		})
+
+		It("Should report a missing profile and create the HPA once it exists", func() {
+			createDeployment(map[string]string{consts.HPAProfile: profile.Name})
+			Eventually(func() string {
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(deployment), deployment); err != nil {
+					return ""
+				}
+				return deployment.Annotations[consts.StatusValidation]
+			}, timeout, interval).Should(ContainSubstring(consts.HPAProfile))
+
+			Expect(k8sClient.Create(ctx, profile)).Should(Succeed())
+			Eventually(minReplicas, timeout, interval).Should(Equal(int32(2)))
+			Eventually(func() bool {
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(deployment), deployment); err != nil {
+					return false
+				}
+				_, ok := deployment.Annotations[consts.StatusValidation]
+				return ok
+			}, timeout, interval).Should(BeFalse())
+		})
+	})
+
+	Context("When filtering workload update events", func() {
+		update := func(mutate func(*appsv1.Deployment)) bool {
+			old := newDeployment("default", "test-predicate-deployment", map[string]string{consts.HPAMaxReplicas: "10"})
+			old.Generation = 1
+			updated := old.DeepCopy()
+			mutate(updated)
+			return workloadPredicate().Update(event.UpdateEvent{ObjectOld: old, ObjectNew: updated})
+		}
+
+		It("Should ignore replica and unrelated annotation changes", func() {
+			Expect(update(func(d *appsv1.Deployment) {
+				replicas := int32(5)
+				d.Spec.Replicas = &replicas
+				d.Generation++
+				d.Annotations["deployment.kubernetes.io/revision"] = "2"
+				d.Status.ReadyReplicas = 5
+			})).Should(BeFalse())
+		})
+
+		It("Should reconcile on autoscale annotation changes", func() {
+			Expect(update(func(d *appsv1.Deployment) {
+				d.Annotations[consts.HPAMaxReplicas] = "15"
+			})).Should(BeTrue())
+			Expect(update(func(d *appsv1.Deployment) {
+				d.Annotations[consts.VPAUpdateMode] = "Off"
+			})).Should(BeTrue())
+		})
+
+		It("Should reconcile on label changes", func() {
+			Expect(update(func(d *appsv1.Deployment) {
+				d.Labels = map[string]string{"tier": "web"}
+			})).Should(BeTrue())
+		})
+
+		It("Should reconcile on container and deletion changes", func() {
+			Expect(update(func(d *appsv1.Deployment) {
+				d.Spec.Template.Spec.Containers = append(d.Spec.Template.Spec.Containers, corev1.Container{Name: "envoy"})
+			})).Should(BeTrue())
+			Expect(update(func(d *appsv1.Deployment) {
+				now := metav1.Now()
+				d.DeletionTimestamp = &now
+			})).Should(BeTrue())
+		})
+	})
 })
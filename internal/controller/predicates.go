@@ -0,0 +1,76 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+	"github.com/infraflows/autoscale-controller/pkg/kube"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// workloadPredicate 过滤工作负载的更新事件，只有以下变化会触发协调：
+// - 自动扩缩容相关的注解发生变化
+// - 标签发生变化，会影响通过 selector 选择工作负载的 AutoscalePolicy
+// - Pod 模板中的容器发生变化，会影响单容器资源指标
+// - 开始删除（deletionTimestamp 被设置）或 finalizer 发生变化
+// 创建、删除事件始终触发协调；副本数（由 HPA 修改）、status 等其他变化会被忽略
+func workloadPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectOld == nil || e.ObjectNew == nil {
+				return true
+			}
+			return !maps.Equal(autoscaleAnnotations(e.ObjectOld), autoscaleAnnotations(e.ObjectNew)) ||
+				!maps.Equal(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels()) ||
+				!slices.Equal(kube.ContainerNames(kube.PodTemplateOf(e.ObjectOld)), kube.ContainerNames(kube.PodTemplateOf(e.ObjectNew))) ||
+				!e.ObjectOld.GetDeletionTimestamp().Equal(e.ObjectNew.GetDeletionTimestamp()) ||
+				!slices.Equal(e.ObjectOld.GetFinalizers(), e.ObjectNew.GetFinalizers())
+		},
+	}
+}
+
+// autoscaleAnnotations 返回对象上与自动扩缩容相关的注解
+func autoscaleAnnotations(obj client.Object) map[string]string {
+	result := map[string]string{}
+	for key, val := range obj.GetAnnotations() {
+		if consts.IsHPAAnnotation(key) || consts.IsVPAAnnotation(key) {
+			result[key] = val
+		}
+	}
+	return result
+}
+
+// namespacePausePredicate 只在命名空间的 autoscale.infraflow.co/paused 标签变化时触发协调
+// 创建、删除事件被忽略，命名空间中的工作负载在创建和删除时会各自协调
+func namespacePausePredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool { return false },
+		DeleteFunc: func(event.DeleteEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectOld == nil || e.ObjectNew == nil {
+				return true
+			}
+			return e.ObjectOld.GetLabels()[consts.PausedLabel] != e.ObjectNew.GetLabels()[consts.PausedLabel]
+		},
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+}
@@ -22,16 +22,32 @@ import (
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	autoscalev1alpha1 "github.com/infraflows/autoscale-controller/pkg/apis/autoscale/v1alpha1"
+	vpav1 "github.com/infraflows/autoscale-controller/pkg/apis/vpa/v1"
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+	"github.com/infraflows/autoscale-controller/pkg/kube"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -44,6 +60,11 @@ var testEnv *envtest.Environment
 var ctx context.Context
 var cancel context.CancelFunc
 
+const (
+	timeout  = time.Second * 10
+	interval = time.Millisecond * 250
+)
+
 func TestControllers(t *testing.T) {
 	RegisterFailHandler(Fail)
 
@@ -57,7 +78,11 @@ var _ = BeforeSuite(func() {
 
 	By("bootstrapping test environment")
 	testEnv = &envtest.Environment{
-		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "config", "crd", "bases")},
+		CRDDirectoryPaths: []string{
+			filepath.Join("..", "..", "config", "crd", "bases"),
+			// VPA CRD，用于验证 --enable-vpa 时的 VPA 管理
+			filepath.Join("..", "..", "test", "crds"),
+		},
 		ErrorIfCRDPathMissing: false,
 
 		// The BinaryAssetsDirectory is only required if you want to run the tests directly
@@ -75,12 +100,38 @@ var _ = BeforeSuite(func() {
 	Expect(err).NotTo(HaveOccurred())
 	Expect(cfg).NotTo(BeNil())
 
+	err = vpav1.AddToScheme(scheme.Scheme)
+	Expect(err).NotTo(HaveOccurred())
+
+	err = autoscalev1alpha1.AddToScheme(scheme.Scheme)
+	Expect(err).NotTo(HaveOccurred())
+
 	// +kubebuilder:scaffold:scheme
 
 	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme.Scheme})
 	Expect(err).NotTo(HaveOccurred())
 	Expect(k8sClient).NotTo(BeNil())
 
+	By("starting the autoscale controller")
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme:  scheme.Scheme,
+		Metrics: metricsserver.Options{BindAddress: "0"},
+	})
+	Expect(err).NotTo(HaveOccurred())
+
+	err = (&AutoScaleReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Event:     mgr.GetEventRecorderFor("AutoScale"),
+		EnableVPA: true,
+	}).SetupWithManager(mgr)
+	Expect(err).NotTo(HaveOccurred())
+
+	go func() {
+		defer GinkgoRecover()
+		Expect(mgr.Start(ctx)).To(Succeed())
+	}()
+
 })
 
 var _ = AfterSuite(func() {
@@ -89,3 +140,112 @@ var _ = AfterSuite(func() {
 	err := testEnv.Stop()
 	Expect(err).NotTo(HaveOccurred())
 })
+
+// newDeployment 返回测试用的 Deployment，Pod 模板中包含一个名为 nginx 的容器
+func newDeployment(namespace, name string, annotations map[string]string) *appsv1.Deployment {
+	replicas := int32(1)
+	labels := map[string]string{"app": name}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Annotations: annotations,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "nginx", Image: "nginx:latest"}},
+				},
+			},
+		},
+	}
+}
+
+// defaultHPAAnnotations 返回最常用的HPA注解：2-10个副本，CPU利用率80%
+func defaultHPAAnnotations() map[string]string {
+	return map[string]string{
+		consts.HPAMinReplicas:                 "2",
+		consts.HPAMaxReplicas:                 "10",
+		consts.HPACpuTargetAverageUtilization: "80",
+	}
+}
+
+// fakeClientBuilder 返回包含objs的 fake client 构建器，apply 请求由 applyPatch 处理
+func fakeClientBuilder(objs ...client.Object) *fake.ClientBuilder {
+	return fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(objs...).
+		WithInterceptorFuncs(interceptor.Funcs{Patch: applyPatch})
+}
+
+// applyPatch 执行Patch，fake client 不支持 server-side apply，apply 请求按对象是否存在转换为 Create 或 Update
+func applyPatch(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch.Type() != types.ApplyPatchType {
+		return c.Patch(ctx, obj, patch, opts...)
+	}
+	existing := obj.DeepCopyObject().(client.Object)
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), existing); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		return c.Create(ctx, obj)
+	}
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	return c.Update(ctx, obj)
+}
+
+// newReconciler 返回使用c的 AutoScaleReconciler 以及记录其事件的 FakeRecorder
+func newReconciler(c client.Client) (*AutoScaleReconciler, *record.FakeRecorder) {
+	recorder := record.NewFakeRecorder(20)
+	return &AutoScaleReconciler{Client: c, Scheme: scheme.Scheme, Event: recorder}, recorder
+}
+
+// reconcileWorkload 协调一次工作负载，要求协调成功
+func reconcileWorkload(r *AutoScaleReconciler, kind string, workload client.Object) ctrl.Result {
+	result, err := r.forKind(kind).Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(workload)})
+	Expect(err).ShouldNot(HaveOccurred())
+	return result
+}
+
+// updateDeployment 读取最新的 deployment 并用 mutate 修改后更新，更新冲突时重试
+func updateDeployment(c client.Client, deployment *appsv1.Deployment, mutate func(*appsv1.Deployment)) {
+	Eventually(func() error {
+		if err := c.Get(ctx, client.ObjectKeyFromObject(deployment), deployment); err != nil {
+			return err
+		}
+		mutate(deployment)
+		return c.Update(ctx, deployment)
+	}, timeout, interval).Should(Succeed())
+}
+
+// getHPA 返回名为key的HPA，要求HPA存在
+func getHPA(c client.Client, key client.ObjectKey) *autoscalingv2.HorizontalPodAutoscaler {
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+	Expect(c.Get(ctx, key, hpa)).Should(Succeed())
+	return hpa
+}
+
+// getStatus 返回工作负载最新的 status.infraflow.co/autoscale 注解
+func getStatus(c client.Client, workload client.Object) *kube.AutoscaleStatus {
+	latest := workload.DeepCopyObject().(client.Object)
+	Expect(c.Get(ctx, client.ObjectKeyFromObject(workload), latest)).Should(Succeed())
+	status, err := kube.ParseAutoscaleStatus(latest.GetAnnotations()[consts.StatusAutoscale])
+	Expect(err).ShouldNot(HaveOccurred())
+	return status
+}
+
+// recordedEvents 取出已记录的事件，格式为 "<type> <reason> <message>"
+func recordedEvents(recorder *record.FakeRecorder) []string {
+	var events []string
+	for {
+		select {
+		case e := <-recorder.Events:
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+	"github.com/infraflows/autoscale-controller/pkg/kube"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupDeploymentDefaultsWebhookWithManager registers the webhook filling in the defaults profile of the
+// namespace on Deployments in the manager. Profiles are read from profilesFile on every request so that
+// changes of the mounted ConfigMap are picked up without a restart; no defaults are applied when it is empty.
+func SetupDeploymentDefaultsWebhookWithManager(mgr ctrl.Manager, profilesFile string) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&appsv1.Deployment{}).
+		WithDefaulter(&DeploymentCustomDefaulter{
+			Client:       mgr.GetClient(),
+			ProfilesFile: profilesFile,
+		}).
+		Complete()
+}
+
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// The defaults are only filled in when Deployments are created, later changes of the profile or of the
+// namespace label do not affect existing Deployments.
+// NOTE: The 'path' attribute must follow a specific pattern and should not be modified directly here.
+// Modifying the path for an invalid path can cause API server errors; failing to locate the webhook.
+// +kubebuilder:webhook:path=/mutate-apps-v1-deployment,mutating=true,failurePolicy=ignore,sideEffects=None,groups=apps,resources=deployments,verbs=create,versions=v1,name=mdeployment-v1.kb.io,admissionReviewVersions=v1
+
+// DeploymentCustomDefaulter struct is responsible for setting the annotations of the defaults profile
+// selected by the namespace label autoscale.infraflow.co/defaults on the Deployment when it is created.
+type DeploymentCustomDefaulter struct {
+	// Client reads the namespace of the Deployment.
+	Client client.Reader
+	// ProfilesFile is the path of the defaults profiles, see kube.LoadDefaultsProfiles.
+	ProfilesFile string
+}
+
+var _ webhook.CustomDefaulter = &DeploymentCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the type.
+// Failures to read the namespace or the profiles are logged and the Deployment is admitted unchanged,
+// consistent with the Ignore failurePolicy of the webhook.
+func (d *DeploymentCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	deployment, ok := obj.(*appsv1.Deployment)
+	if !ok {
+		return fmt.Errorf("expected a Deployment object but got %T", obj)
+	}
+	if d.ProfilesFile == "" {
+		return nil
+	}
+
+	namespace := deployment.GetNamespace()
+	if req, err := admission.RequestFromContext(ctx); err == nil && req.Namespace != "" {
+		namespace = req.Namespace
+	}
+	ns := &corev1.Namespace{}
+	if err := d.Client.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+		workloadlog.Error(err, "Failed to get namespace, defaults not applied", "namespace", namespace)
+		return nil
+	}
+	name, ok := ns.GetLabels()[consts.DefaultsProfileLabel]
+	if !ok || name == "" {
+		return nil
+	}
+
+	profiles, err := kube.LoadDefaultsProfiles(d.ProfilesFile)
+	if err != nil {
+		workloadlog.Error(err, "Failed to load defaults profiles, defaults not applied", "file", d.ProfilesFile)
+		return nil
+	}
+	defaults, ok := profiles[name]
+	if !ok {
+		workloadlog.Info("Defaults profile of namespace not found", "namespace", namespace, "profile", name)
+		return nil
+	}
+
+	annotations, applied := kube.ApplyDefaults(deployment.GetAnnotations(), defaults)
+	if len(applied) == 0 {
+		return nil
+	}
+	annotations[consts.DefaultsProfileAnnotation] = name
+	deployment.SetAnnotations(annotations)
+	workloadlog.V(1).Info("Defaults profile applied to Deployment", "namespace", namespace,
+		"name", deployment.GetName(), "profile", name, "annotations", applied)
+	return nil
+}
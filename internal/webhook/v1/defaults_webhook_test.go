@@ -0,0 +1,148 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testProfiles = `
+web-standard:
+  hpa.infraflow.co/minReplicas: "2"
+  hpa.infraflow.co/maxReplicas: "10"
+  hpa.infraflow.co/cpu.targetAverageUtilization: "70"
+`
+
+var _ = Describe("Deployment Defaults Webhook", func() {
+	var (
+		ctx       context.Context
+		defaulter *DeploymentCustomDefaulter
+	)
+
+	newNamespace := func(name string, labels map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	newDeployment := func(namespace string, annotations map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-deployment",
+				Namespace:   namespace,
+				Annotations: annotations,
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		profilesFile := filepath.Join(GinkgoT().TempDir(), "profiles.yaml")
+		Expect(os.WriteFile(profilesFile, []byte(testProfiles), 0o600)).To(Succeed())
+
+		defaulter = &DeploymentCustomDefaulter{
+			Client: fake.NewClientBuilder().WithObjects(
+				newNamespace("web", map[string]string{consts.DefaultsProfileLabel: "web-standard"}),
+				newNamespace("unknown", map[string]string{consts.DefaultsProfileLabel: "batch-burst"}),
+				newNamespace("plain", nil),
+			).Build(),
+			ProfilesFile: profilesFile,
+		}
+	})
+
+	Context("When creating a Deployment under Defaulting Webhook", func() {
+		It("Should fill in the defaults of the namespace profile", func() {
+			deployment := newDeployment("web", nil)
+			Expect(defaulter.Default(ctx, deployment)).To(Succeed())
+			Expect(deployment.Annotations).To(Equal(map[string]string{
+				consts.HPAMinReplicas:                 "2",
+				consts.HPAMaxReplicas:                 "10",
+				consts.HPACpuTargetAverageUtilization: "70",
+				consts.DefaultsProfileAnnotation:      "web-standard",
+			}))
+		})
+
+		It("Should not overwrite explicit annotations", func() {
+			deployment := newDeployment("web", map[string]string{
+				consts.HPAMaxReplicas:                             "20",
+				"cpu.hpa.infraflow.co/target-average-utilization": "50",
+			})
+			Expect(defaulter.Default(ctx, deployment)).To(Succeed())
+			Expect(deployment.Annotations).To(HaveKeyWithValue(consts.HPAMaxReplicas, "20"))
+			Expect(deployment.Annotations).To(HaveKeyWithValue(consts.HPAMinReplicas, "2"))
+			Expect(deployment.Annotations).NotTo(HaveKey(consts.HPACpuTargetAverageUtilization))
+		})
+
+		It("Should not fill in a minReplicas greater than the explicit maxReplicas", func() {
+			deployment := newDeployment("web", map[string]string{consts.HPAMaxReplicas: "1"})
+			Expect(defaulter.Default(ctx, deployment)).To(Succeed())
+			Expect(deployment.Annotations).NotTo(HaveKey(consts.HPAMinReplicas))
+			Expect(deployment.Annotations).To(HaveKeyWithValue(consts.HPAMaxReplicas, "1"))
+		})
+
+		It("Should leave Deployments alone in namespaces without profile", func() {
+			deployment := newDeployment("plain", nil)
+			Expect(defaulter.Default(ctx, deployment)).To(Succeed())
+			Expect(deployment.Annotations).To(BeEmpty())
+		})
+
+		It("Should leave Deployments alone when the profile does not exist", func() {
+			deployment := newDeployment("unknown", nil)
+			Expect(defaulter.Default(ctx, deployment)).To(Succeed())
+			Expect(deployment.Annotations).To(BeEmpty())
+		})
+
+		It("Should leave Deployments alone when no profiles are configured", func() {
+			defaulter.ProfilesFile = ""
+			deployment := newDeployment("web", nil)
+			Expect(defaulter.Default(ctx, deployment)).To(Succeed())
+			Expect(deployment.Annotations).To(BeEmpty())
+		})
+
+		It("Should not mark Deployments whose annotations override every default", func() {
+			deployment := newDeployment("web", map[string]string{
+				consts.HPAMinReplicas:                 "3",
+				consts.HPAMaxReplicas:                 "6",
+				consts.HPACpuTargetAverageUtilization: "50",
+			})
+			Expect(defaulter.Default(ctx, deployment)).To(Succeed())
+			Expect(deployment.Annotations).NotTo(HaveKey(consts.DefaultsProfileAnnotation))
+		})
+
+		It("Should admit Deployments unchanged when the profiles cannot be loaded", func() {
+			Expect(os.WriteFile(defaulter.ProfilesFile,
+				[]byte("web-standard:\n  hpa.infraflow.co/maxReplica: \"10\"\n"), 0o600)).To(Succeed())
+			deployment := newDeployment("web", nil)
+			Expect(defaulter.Default(ctx, deployment)).To(Succeed())
+			Expect(deployment.Annotations).To(BeEmpty())
+		})
+
+		It("Should admit Deployments unchanged when the namespace cannot be read", func() {
+			deployment := newDeployment("missing", nil)
+			Expect(defaulter.Default(ctx, deployment)).To(Succeed())
+			Expect(deployment.Annotations).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,169 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	autoscalev1alpha1 "github.com/infraflows/autoscale-controller/pkg/apis/autoscale/v1alpha1"
+	"github.com/infraflows/autoscale-controller/pkg/kube"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupPolicyWebhooksWithManager registers the validating webhooks for AutoscalePolicy,
+// ClusterAutoscaleProfile and ClusterAutoscaleCalendar in the manager.
+func SetupPolicyWebhooksWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewWebhookManagedBy(mgr).For(&autoscalev1alpha1.AutoscalePolicy{}).
+		WithValidator(&AutoscalePolicyCustomValidator{}).
+		Complete(); err != nil {
+		return err
+	}
+	if err := ctrl.NewWebhookManagedBy(mgr).For(&autoscalev1alpha1.ClusterAutoscaleProfile{}).
+		WithValidator(&ClusterAutoscaleProfileCustomValidator{}).
+		Complete(); err != nil {
+		return err
+	}
+	return ctrl.NewWebhookManagedBy(mgr).For(&autoscalev1alpha1.ClusterAutoscaleCalendar{}).
+		WithValidator(&ClusterAutoscaleCalendarCustomValidator{}).
+		Complete()
+}
+
+// The webhooks validate the cron expressions and time zones of spec.hpa.schedules, and the time zone
+// and dates of the calendar entries, which the CRD schema cannot check. Other fields are validated by
+// the schema.
+// NOTE: The 'path' attribute must follow a specific pattern and should not be modified directly here.
+// Modifying the path for an invalid path can cause API server errors; failing to locate the webhook.
+// +kubebuilder:webhook:path=/validate-autoscale-infraflow-co-v1alpha1-autoscalepolicy,mutating=false,failurePolicy=ignore,sideEffects=None,groups=autoscale.infraflow.co,resources=autoscalepolicies,verbs=create;update,versions=v1alpha1,name=vautoscalepolicy-v1alpha1.kb.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-autoscale-infraflow-co-v1alpha1-clusterautoscaleprofile,mutating=false,failurePolicy=ignore,sideEffects=None,groups=autoscale.infraflow.co,resources=clusterautoscaleprofiles,verbs=create;update,versions=v1alpha1,name=vclusterautoscaleprofile-v1alpha1.kb.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-autoscale-infraflow-co-v1alpha1-clusterautoscalecalendar,mutating=false,failurePolicy=ignore,sideEffects=None,groups=autoscale.infraflow.co,resources=clusterautoscalecalendars,verbs=create;update,versions=v1alpha1,name=vclusterautoscalecalendar-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// AutoscalePolicyCustomValidator struct is responsible for validating the AutoscalePolicy resource
+// when it is created or updated.
+type AutoscalePolicyCustomValidator struct{}
+
+var _ webhook.CustomValidator = &AutoscalePolicyCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type.
+func (v *AutoscalePolicyCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(obj)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type.
+func (v *AutoscalePolicyCustomValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(newObj)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type.
+func (v *AutoscalePolicyCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate rejects the policy when some of its schedules are invalid.
+func (v *AutoscalePolicyCustomValidator) validate(obj runtime.Object) error {
+	policy, ok := obj.(*autoscalev1alpha1.AutoscalePolicy)
+	if !ok {
+		return fmt.Errorf("expected an AutoscalePolicy object but got %T", obj)
+	}
+	workloadlog.V(1).Info("Validation for AutoscalePolicy", "namespace", policy.Namespace, "name", policy.Name)
+
+	if policy.Spec.HPA == nil {
+		return nil
+	}
+	errs := kube.ValidateSchedules(policy.Spec.HPA.Schedules, field.NewPath("spec", "hpa", "schedules"))
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(autoscalev1alpha1.GroupVersion.WithKind("AutoscalePolicy").GroupKind(), policy.Name, errs)
+}
+
+// ClusterAutoscaleProfileCustomValidator struct is responsible for validating the ClusterAutoscaleProfile
+// resource when it is created or updated.
+type ClusterAutoscaleProfileCustomValidator struct{}
+
+var _ webhook.CustomValidator = &ClusterAutoscaleProfileCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type.
+func (v *ClusterAutoscaleProfileCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(obj)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type.
+func (v *ClusterAutoscaleProfileCustomValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(newObj)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type.
+func (v *ClusterAutoscaleProfileCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate rejects the profile when some of its schedules are invalid.
+func (v *ClusterAutoscaleProfileCustomValidator) validate(obj runtime.Object) error {
+	profile, ok := obj.(*autoscalev1alpha1.ClusterAutoscaleProfile)
+	if !ok {
+		return fmt.Errorf("expected a ClusterAutoscaleProfile object but got %T", obj)
+	}
+	workloadlog.V(1).Info("Validation for ClusterAutoscaleProfile", "name", profile.Name)
+
+	errs := kube.ValidateSchedules(profile.Spec.HPA.Schedules, field.NewPath("spec", "hpa", "schedules"))
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(autoscalev1alpha1.GroupVersion.WithKind("ClusterAutoscaleProfile").GroupKind(), profile.Name, errs)
+}
+
+// ClusterAutoscaleCalendarCustomValidator struct is responsible for validating the ClusterAutoscaleCalendar
+// resource when it is created or updated.
+type ClusterAutoscaleCalendarCustomValidator struct{}
+
+var _ webhook.CustomValidator = &ClusterAutoscaleCalendarCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type.
+func (v *ClusterAutoscaleCalendarCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(obj)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type.
+func (v *ClusterAutoscaleCalendarCustomValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(newObj)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type.
+func (v *ClusterAutoscaleCalendarCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate rejects the calendar when its time zone or some of its entry dates are invalid.
+func (v *ClusterAutoscaleCalendarCustomValidator) validate(obj runtime.Object) error {
+	calendar, ok := obj.(*autoscalev1alpha1.ClusterAutoscaleCalendar)
+	if !ok {
+		return fmt.Errorf("expected a ClusterAutoscaleCalendar object but got %T", obj)
+	}
+	workloadlog.V(1).Info("Validation for ClusterAutoscaleCalendar", "name", calendar.Name)
+
+	errs := kube.ValidateCalendar(calendar)
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(autoscalev1alpha1.GroupVersion.WithKind("ClusterAutoscaleCalendar").GroupKind(), calendar.Name, errs)
+}
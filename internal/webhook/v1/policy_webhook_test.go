@@ -0,0 +1,143 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	autoscalev1alpha1 "github.com/infraflows/autoscale-controller/pkg/apis/autoscale/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Policy Webhook", func() {
+	var ctx context.Context
+
+	newWindow := func(schedule, timeZone string) autoscalev1alpha1.ScheduleWindow {
+		maxReplicas := int32(20)
+		return autoscalev1alpha1.ScheduleWindow{
+			Schedule:    schedule,
+			TimeZone:    timeZone,
+			Duration:    metav1.Duration{Duration: 10 * time.Hour},
+			MaxReplicas: &maxReplicas,
+		}
+	}
+	newPolicy := func(windows ...autoscalev1alpha1.ScheduleWindow) *autoscalev1alpha1.AutoscalePolicy {
+		return &autoscalev1alpha1.AutoscalePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-policy", Namespace: "default"},
+			Spec: autoscalev1alpha1.AutoscalePolicySpec{
+				HPA: &autoscalev1alpha1.HPASpec{MaxReplicas: 10, Schedules: windows},
+			},
+		}
+	}
+	newProfile := func(windows ...autoscalev1alpha1.ScheduleWindow) *autoscalev1alpha1.ClusterAutoscaleProfile {
+		return &autoscalev1alpha1.ClusterAutoscaleProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-profile"},
+			Spec: autoscalev1alpha1.ClusterAutoscaleProfileSpec{
+				HPA: autoscalev1alpha1.HPASpec{MaxReplicas: 10, Schedules: windows},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	Context("When validating an AutoscalePolicy", func() {
+		validator := &AutoscalePolicyCustomValidator{}
+
+		It("Should admit valid schedules", func() {
+			_, err := validator.ValidateCreate(ctx, newPolicy(newWindow("0 8 * * 1-5", "Asia/Shanghai")))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should admit policies without an HPA", func() {
+			_, err := validator.ValidateCreate(ctx, &autoscalev1alpha1.AutoscalePolicy{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny an invalid cron expression", func() {
+			_, err := validator.ValidateCreate(ctx, newPolicy(newWindow("0 8 * *", "")))
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.hpa.schedules[0].schedule"))
+		})
+
+		It("Should deny an unknown time zone on update", func() {
+			oldPolicy := newPolicy(newWindow("0 8 * * 1-5", "Asia/Shanghai"))
+			newPolicy := newPolicy(newWindow("0 8 * * 1-5", "Asia/Shanghai"), newWindow("0 20 * * *", "Mars/Olympus"))
+			_, err := validator.ValidateUpdate(ctx, oldPolicy, newPolicy)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.hpa.schedules[1].timeZone"))
+		})
+	})
+
+	Context("When validating a ClusterAutoscaleProfile", func() {
+		validator := &ClusterAutoscaleProfileCustomValidator{}
+
+		It("Should admit valid schedules", func() {
+			_, err := validator.ValidateCreate(ctx, newProfile(newWindow("@daily", "")))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny an invalid cron expression and time zone", func() {
+			_, err := validator.ValidateCreate(ctx, newProfile(newWindow("0 25 * * *", "Asia/Nowhere")))
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.hpa.schedules[0].schedule"))
+			Expect(err.Error()).To(ContainSubstring("spec.hpa.schedules[0].timeZone"))
+		})
+
+		It("Should reject objects of another kind", func() {
+			_, err := validator.ValidateCreate(ctx, newPolicy())
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("When validating a ClusterAutoscaleCalendar", func() {
+		validator := &ClusterAutoscaleCalendarCustomValidator{}
+		newCalendar := func(timeZone string, entries ...autoscalev1alpha1.CalendarEntry) *autoscalev1alpha1.ClusterAutoscaleCalendar {
+			return &autoscalev1alpha1.ClusterAutoscaleCalendar{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-calendar"},
+				Spec:       autoscalev1alpha1.ClusterAutoscaleCalendarSpec{TimeZone: timeZone, Entries: entries},
+			}
+		}
+		minReplicas := int32(30)
+
+		It("Should admit a valid calendar", func() {
+			_, err := validator.ValidateCreate(ctx, newCalendar("Asia/Shanghai",
+				autoscalev1alpha1.CalendarEntry{Name: "black-friday", Start: "2025-11-28", End: "2025-12-01", MinReplicas: &minReplicas}))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny an unknown time zone on update", func() {
+			oldCalendar := newCalendar("Asia/Shanghai")
+			_, err := validator.ValidateUpdate(ctx, oldCalendar, newCalendar("Asia/Shangai"))
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.timeZone"))
+		})
+
+		It("Should deny an entry with a date that does not exist", func() {
+			_, err := validator.ValidateCreate(ctx, newCalendar("",
+				autoscalev1alpha1.CalendarEntry{Name: "february", Start: "2025-02-01", End: "2025-02-30", MinReplicas: &minReplicas}))
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.entries[0]"))
+		})
+	})
+})
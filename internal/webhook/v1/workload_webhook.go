@@ -0,0 +1,145 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+	"github.com/infraflows/autoscale-controller/pkg/kube"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var workloadlog = logf.Log.WithName("workload-resource")
+
+// SetupWorkloadWebhooksWithManager registers the annotation validating webhooks for Deployment,
+// StatefulSet and DaemonSet in the manager.
+func SetupWorkloadWebhooksWithManager(mgr ctrl.Manager) error {
+	for _, kind := range kube.WorkloadKinds {
+		if err := ctrl.NewWebhookManagedBy(mgr).For(kube.NewWorkload(kind)).
+			WithValidator(&WorkloadCustomValidator{Kind: kind}).
+			Complete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The webhooks only validate the autoscale annotations. failurePolicy is Ignore so that workloads can
+// still be deployed while the controller is unavailable; invalid annotations are then reported by
+// the controller through Events and the status.infraflow.co/validation annotation.
+// NOTE: The 'path' attribute must follow a specific pattern and should not be modified directly here.
+// Modifying the path for an invalid path can cause API server errors; failing to locate the webhook.
+// +kubebuilder:webhook:path=/validate-apps-v1-deployment,mutating=false,failurePolicy=ignore,sideEffects=None,groups=apps,resources=deployments,verbs=create;update,versions=v1,name=vdeployment-v1.kb.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-apps-v1-statefulset,mutating=false,failurePolicy=ignore,sideEffects=None,groups=apps,resources=statefulsets,verbs=create;update,versions=v1,name=vstatefulset-v1.kb.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-apps-v1-daemonset,mutating=false,failurePolicy=ignore,sideEffects=None,groups=apps,resources=daemonsets,verbs=create;update,versions=v1,name=vdaemonset-v1.kb.io,admissionReviewVersions=v1
+
+// WorkloadCustomValidator struct is responsible for validating the autoscale annotations of a
+// workload kind when it is created or updated.
+type WorkloadCustomValidator struct {
+	// Kind is the workload kind validated, one of kube.WorkloadKinds.
+	Kind string
+}
+
+var _ webhook.CustomValidator = &WorkloadCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type.
+func (v *WorkloadCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	workload, err := v.workload(obj)
+	if err != nil {
+		return nil, err
+	}
+	workloadlog.V(1).Info("Validation for workload upon creation", "kind", v.Kind, "name", workload.GetName())
+
+	return v.validate(workload)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type.
+// Workloads whose autoscale annotations and containers are not changed are admitted as is, so that
+// workloads created before the webhook was installed can still be rolled out. Renaming or removing a
+// container is validated as well, since the container metric annotations refer to containers by name.
+func (v *WorkloadCustomValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldWorkload, err := v.workload(oldObj)
+	if err != nil {
+		return nil, err
+	}
+	workload, err := v.workload(newObj)
+	if err != nil {
+		return nil, err
+	}
+	workloadlog.V(1).Info("Validation for workload upon update", "kind", v.Kind, "name", workload.GetName())
+
+	if maps.Equal(autoscaleAnnotations(oldWorkload), autoscaleAnnotations(workload)) &&
+		slices.Equal(containerNames(oldWorkload), containerNames(workload)) {
+		return nil, nil
+	}
+	return v.validate(workload)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type.
+func (v *WorkloadCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate rejects the workload when some of its autoscale annotations are invalid.
+func (v *WorkloadCustomValidator) validate(workload client.Object) (admission.Warnings, error) {
+	errs, warnings := kube.ValidateAnnotations(workload, v.Kind)
+	if len(errs) == 0 {
+		return warnings, nil
+	}
+	return warnings, apierrors.NewInvalid(schema.GroupKind{Group: appsv1.GroupName, Kind: v.Kind}, workload.GetName(), errs)
+}
+
+// workload checks that the object is of the validated kind.
+func (v *WorkloadCustomValidator) workload(obj runtime.Object) (client.Object, error) {
+	expected := kube.NewWorkload(v.Kind)
+	if workload, ok := obj.(client.Object); ok && expected != nil && fmt.Sprintf("%T", workload) == fmt.Sprintf("%T", expected) {
+		return workload, nil
+	}
+	return nil, fmt.Errorf("expected a %s object but got %T", v.Kind, obj)
+}
+
+// autoscaleAnnotations returns the annotations of the object handled by the controller.
+func autoscaleAnnotations(obj client.Object) map[string]string {
+	result := map[string]string{}
+	for key, val := range obj.GetAnnotations() {
+		if consts.IsHPAAnnotation(key) || consts.IsVPAAnnotation(key) {
+			result[key] = val
+		}
+	}
+	return result
+}
+
+// containerNames returns the names of the containers of the workload that container metric annotations
+// may refer to, sorted. Sidecar init containers are included, see kube.ContainerNames.
+func containerNames(obj client.Object) []string {
+	names := kube.ContainerNames(kube.PodTemplateOf(obj))
+	slices.Sort(names)
+	return names
+}
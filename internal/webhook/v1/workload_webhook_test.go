@@ -0,0 +1,232 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+	"github.com/infraflows/autoscale-controller/pkg/kube"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Workload Webhook", func() {
+	var (
+		ctx       context.Context
+		validator *WorkloadCustomValidator
+	)
+
+	newDeployment := func(annotations map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-deployment",
+				Namespace:   "default",
+				Annotations: annotations,
+			},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "app", Image: "nginx"}},
+					},
+				},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		validator = &WorkloadCustomValidator{Kind: kube.KindDeployment}
+	})
+
+	Context("When creating a Deployment under Validating Webhook", func() {
+		It("Should admit valid annotations", func() {
+			warnings, err := validator.ValidateCreate(ctx, newDeployment(map[string]string{
+				consts.HPAMinReplicas:                 "2",
+				consts.HPAMaxReplicas:                 "10",
+				consts.HPACpuTargetAverageUtilization: "80",
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("Should admit workloads without autoscale annotations", func() {
+			_, err := validator.ValidateCreate(ctx, newDeployment(nil))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny minReplicas greater than maxReplicas", func() {
+			_, err := validator.ValidateCreate(ctx, newDeployment(map[string]string{
+				consts.HPAMinReplicas: "5",
+				consts.HPAMaxReplicas: "2",
+			}))
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring(consts.HPAMaxReplicas))
+		})
+
+		It("Should deny invalid quantities", func() {
+			_, err := validator.ValidateCreate(ctx, newDeployment(map[string]string{
+				consts.HPAMaxReplicas:           "10",
+				consts.HPACpuTargetAverageValue: "half-a-core",
+			}))
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring(consts.HPACpuTargetAverageValue))
+		})
+
+		It("Should deny an invalid JSON resourcePolicy", func() {
+			_, err := validator.ValidateCreate(ctx, newDeployment(map[string]string{
+				consts.VPAResourcePolicy: `{"containerPolicies": [`,
+			}))
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring(consts.VPAResourcePolicy))
+		})
+
+		It("Should deny invalid scaling policies", func() {
+			_, err := validator.ValidateCreate(ctx, newDeployment(map[string]string{
+				consts.HPAMaxReplicas:       "10",
+				consts.HPAScaleUpPolicies:   `[{"type":"Pod","value":4,"periodSeconds":60}]`,
+				consts.HPAScaleDownPolicies: `[{"type":"Percent","value":0,"periodSeconds":3600}]`,
+			}))
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("[0].type must be one of Pods, Percent"))
+			Expect(err.Error()).To(ContainSubstring("[0].value must be greater than 0"))
+			Expect(err.Error()).To(ContainSubstring("[0].periodSeconds must be between 1 and 1800"))
+		})
+
+		It("Should deny unknown annotation keys", func() {
+			_, err := validator.ValidateCreate(ctx, newDeployment(map[string]string{
+				consts.HPAMaxReplicas:         "10",
+				"hpa.infraflow.co/maxReplica": "10",
+			}))
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("hpa.infraflow.co/maxReplica"))
+		})
+
+		It("Should report every invalid annotation at once", func() {
+			_, err := validator.ValidateCreate(ctx, newDeployment(map[string]string{
+				consts.HPAMaxReplicas:              "ten",
+				consts.HPAMemoryTargetAverageValue: "lots",
+			}))
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			var statusErr *apierrors.StatusError
+			Expect(errors.As(err, &statusErr)).To(BeTrue())
+			Expect(statusErr.ErrStatus.Details.Causes).To(HaveLen(2))
+		})
+
+		It("Should warn about deprecated annotations", func() {
+			warnings, err := validator.ValidateCreate(ctx, newDeployment(map[string]string{
+				"hpa.infraflow.co/max-replicas": "10",
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ContainElement(ContainSubstring("hpa.infraflow.co/max-replicas")))
+		})
+
+		It("Should warn about HPA annotations on a DaemonSet", func() {
+			validator = &WorkloadCustomValidator{Kind: kube.KindDaemonSet}
+			daemonSet := &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-daemonset",
+					Namespace:   "default",
+					Annotations: map[string]string{consts.HPAMaxReplicas: "10"},
+				},
+			}
+			warnings, err := validator.ValidateCreate(ctx, daemonSet)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).NotTo(BeEmpty())
+		})
+
+		It("Should reject objects of another kind", func() {
+			_, err := validator.ValidateCreate(ctx, &appsv1.StatefulSet{})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("When updating a Deployment under Validating Webhook", func() {
+		It("Should admit updates that do not change invalid annotations", func() {
+			oldDeployment := newDeployment(map[string]string{consts.HPAMaxReplicas: "ten"})
+			newDeployment := oldDeployment.DeepCopy()
+			newDeployment.Spec.Template.Spec.Containers[0].Image = "nginx:latest"
+			_, err := validator.ValidateUpdate(ctx, oldDeployment, newDeployment)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny updates that introduce invalid annotations", func() {
+			oldDeployment := newDeployment(map[string]string{consts.HPAMaxReplicas: "10"})
+			newDeployment := newDeployment(map[string]string{consts.HPAMaxReplicas: "ten"})
+			_, err := validator.ValidateUpdate(ctx, oldDeployment, newDeployment)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		})
+
+		It("Should deny renaming a container referenced by container metric annotations", func() {
+			oldDeployment := newDeployment(map[string]string{
+				consts.HPAMaxReplicas: "10",
+				consts.ContainerKey("app", consts.HPACpuTargetAverageUtilization): "70",
+			})
+			newDeployment := oldDeployment.DeepCopy()
+			newDeployment.Spec.Template.Spec.Containers[0].Name = "web"
+			_, err := validator.ValidateUpdate(ctx, oldDeployment, newDeployment)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring(`container "app" does not exist`))
+		})
+
+		It("Should deny removing a container referenced by container metric annotations", func() {
+			oldDeployment := newDeployment(map[string]string{
+				consts.HPAMaxReplicas: "10",
+				consts.ContainerKey("sidecar", consts.HPACpuTargetAverageUtilization): "70",
+			})
+			oldDeployment.Spec.Template.Spec.Containers = append(oldDeployment.Spec.Template.Spec.Containers,
+				corev1.Container{Name: "sidecar", Image: "envoy"})
+			newDeployment := oldDeployment.DeepCopy()
+			newDeployment.Spec.Template.Spec.Containers = newDeployment.Spec.Template.Spec.Containers[:1]
+			_, err := validator.ValidateUpdate(ctx, oldDeployment, newDeployment)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		})
+
+		It("Should deny renaming a sidecar container referenced by container metric annotations", func() {
+			always := corev1.ContainerRestartPolicyAlways
+			oldDeployment := newDeployment(map[string]string{
+				consts.HPAMaxReplicas: "10",
+				consts.ContainerKey("envoy", consts.HPACpuTargetAverageUtilization): "70",
+			})
+			oldDeployment.Spec.Template.Spec.InitContainers = []corev1.Container{
+				{Name: "envoy", Image: "envoy", RestartPolicy: &always},
+			}
+			newDeployment := oldDeployment.DeepCopy()
+			newDeployment.Spec.Template.Spec.InitContainers[0].Name = "proxy"
+			_, err := validator.ValidateUpdate(ctx, oldDeployment, newDeployment)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring(`container "envoy" does not exist`))
+		})
+
+		It("Should admit reordering the containers", func() {
+			oldDeployment := newDeployment(map[string]string{consts.HPAMaxReplicas: "ten"})
+			oldDeployment.Spec.Template.Spec.Containers = append(oldDeployment.Spec.Template.Spec.Containers,
+				corev1.Container{Name: "sidecar", Image: "envoy"})
+			newDeployment := oldDeployment.DeepCopy()
+			containers := newDeployment.Spec.Template.Spec.Containers
+			containers[0], containers[1] = containers[1], containers[0]
+			_, err := validator.ValidateUpdate(ctx, oldDeployment, newDeployment)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,163 @@
+/*
+Copyright 2025 infraflows team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	vpav1 "github.com/infraflows/autoscale-controller/pkg/apis/vpa/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AutoscalePolicySpec defines the autoscalers of the workloads selected by the policy.
+// +kubebuilder:validation:XValidation:rule="has(self.targetRef) != has(self.selector)",message="exactly one of targetRef or selector must be set"
+// +kubebuilder:validation:XValidation:rule="has(self.hpa) || has(self.vpa)",message="at least one of hpa or vpa must be set"
+type AutoscalePolicySpec struct {
+	// TargetRef selects a single workload in the namespace of the policy by kind and name.
+	// +optional
+	TargetRef *PolicyTargetReference `json:"targetRef,omitempty"`
+
+	// Selector selects the Deployments, StatefulSets and DaemonSets in the namespace of the policy
+	// whose labels match.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// HPA is the HorizontalPodAutoscaler created for each selected workload.
+	// +optional
+	HPA *HPASpec `json:"hpa,omitempty"`
+
+	// VPA is the VerticalPodAutoscaler created for each selected workload.
+	// +optional
+	VPA *VPASpec `json:"vpa,omitempty"`
+}
+
+// PolicyTargetReference identifies a workload in the namespace of the policy.
+type PolicyTargetReference struct {
+	// Kind of the workload.
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet;DaemonSet
+	Kind string `json:"kind"`
+
+	// Name of the workload.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// HPASpec is the typed form of the hpa.infraflow.co annotations.
+// +kubebuilder:validation:XValidation:rule="!has(self.minReplicas) || self.minReplicas <= self.maxReplicas",message="minReplicas must be less than or equal to maxReplicas"
+type HPASpec struct {
+	// MinReplicas is the lower limit for the number of replicas.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper limit for the number of replicas.
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// Metrics contains the specifications used to calculate the desired replica count.
+	// +listType=atomic
+	// +optional
+	Metrics []autoscalingv2.MetricSpec `json:"metrics,omitempty"`
+
+	// Behavior configures the scaling behavior in both up and down directions.
+	// +optional
+	Behavior *autoscalingv2.HorizontalPodAutoscalerBehavior `json:"behavior,omitempty"`
+
+	// Schedules override minReplicas and maxReplicas during recurring time windows. When several
+	// windows are active at the same time, the first one in the list takes effect.
+	// +listType=atomic
+	// +optional
+	Schedules []ScheduleWindow `json:"schedules,omitempty"`
+
+	// Calendar is the name of a ClusterAutoscaleCalendar whose active entry sets floors for minReplicas
+	// and maxReplicas, applied after the active schedule: each limit is raised to the value of the entry
+	// and never lowered. The hpa.infraflow.co/calendar annotation of the workload overrides it.
+	// +optional
+	Calendar string `json:"calendar,omitempty"`
+}
+
+// ScheduleWindow is a recurring time window during which the replica limits of the HPA are overridden.
+// +kubebuilder:validation:XValidation:rule="has(self.minReplicas) || has(self.maxReplicas)",message="at least one of minReplicas or maxReplicas must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.minReplicas) || !has(self.maxReplicas) || self.minReplicas <= self.maxReplicas",message="minReplicas must be less than or equal to maxReplicas"
+type ScheduleWindow struct {
+	// Name identifies the window in the status of the workloads, defaults to the schedule.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Schedule is the cron expression of the window starts, in the standard 5-field format
+	// (minute hour day-of-month month day-of-week), e.g. "0 8 * * 1-5".
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// TimeZone is the IANA name of the time zone the schedule is evaluated in, e.g. "Asia/Shanghai".
+	// Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// Duration is how long the window lasts after each start, a whole number of minutes, e.g. "10h".
+	Duration metav1.Duration `json:"duration"`
+
+	// MinReplicas overrides the minReplicas of the HPA while the window is active.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas overrides the maxReplicas of the HPA while the window is active.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+}
+
+// VPASpec is the typed form of the vpa.infraflow.co annotations.
+type VPASpec struct {
+	// UpdateMode controls when the autoscaler applies changes to the pod resources. Defaults to Auto.
+	// +kubebuilder:validation:Enum=Auto;Initial;Off
+	// +optional
+	UpdateMode *vpav1.UpdateMode `json:"updateMode,omitempty"`
+
+	// ResourcePolicy controls how the autoscaler computes the recommended resources.
+	// +optional
+	ResourcePolicy *vpav1.PodResourcePolicy `json:"resourcePolicy,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=asp
+// +kubebuilder:printcolumn:name="Target",type=string,JSONPath=`.spec.targetRef.name`
+// +kubebuilder:printcolumn:name="MinReplicas",type=integer,JSONPath=`.spec.hpa.minReplicas`
+// +kubebuilder:printcolumn:name="MaxReplicas",type=integer,JSONPath=`.spec.hpa.maxReplicas`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// AutoscalePolicy is the Schema for the autoscalepolicies API. It is a typed alternative to the
+// hpa.infraflow.co and vpa.infraflow.co annotations of the workloads.
+type AutoscalePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AutoscalePolicySpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// AutoscalePolicyList contains a list of AutoscalePolicy.
+type AutoscalePolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AutoscalePolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AutoscalePolicy{}, &AutoscalePolicyList{})
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2025 infraflows team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterAutoscaleCalendarSpec defines the dated entries of the calendar.
+type ClusterAutoscaleCalendarSpec struct {
+	// TimeZone is the IANA name of the time zone the dates of the entries are evaluated in,
+	// e.g. "Asia/Shanghai". Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// Entries are the date ranges during which the replica limits of the HPA are overridden. When
+	// several entries are active at the same time, the first one in the list takes effect.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Entries []CalendarEntry `json:"entries,omitempty"`
+}
+
+// CalendarEntry is a date range during which the replica limits of the HPA are overridden.
+// +kubebuilder:validation:XValidation:rule="self.end >= self.start",message="end must not be before start"
+// +kubebuilder:validation:XValidation:rule="has(self.minReplicas) || has(self.maxReplicas)",message="at least one of minReplicas or maxReplicas must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.minReplicas) || !has(self.maxReplicas) || self.minReplicas <= self.maxReplicas",message="minReplicas must be less than or equal to maxReplicas"
+type CalendarEntry struct {
+	// Name identifies the entry in the status and metrics of the workloads, e.g. "black-friday".
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Start is the first day of the entry in the format YYYY-MM-DD. The entry starts at midnight
+	// in the time zone of the calendar.
+	// +kubebuilder:validation:Pattern=`^[0-9]{4}-[0-9]{2}-[0-9]{2}$`
+	Start string `json:"start"`
+
+	// End is the last day of the entry in the format YYYY-MM-DD, inclusive. The entry ends at the
+	// following midnight in the time zone of the calendar.
+	// +kubebuilder:validation:Pattern=`^[0-9]{4}-[0-9]{2}-[0-9]{2}$`
+	End string `json:"end"`
+
+	// MinReplicas is the floor of the minReplicas of the HPA while the entry is active.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the floor of the maxReplicas of the HPA while the entry is active.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=cac
+// +kubebuilder:printcolumn:name="TimeZone",type=string,JSONPath=`.spec.timeZone`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ClusterAutoscaleCalendar is the Schema for the clusterautoscalecalendars API. It is a named set of
+// dated entries, e.g. holidays or sales events, which set floors for the replica limits of the HPAs of
+// the workloads referencing it. Active entries are applied after the schedules of the HPA.
+type ClusterAutoscaleCalendar struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ClusterAutoscaleCalendarSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterAutoscaleCalendarList contains a list of ClusterAutoscaleCalendar.
+type ClusterAutoscaleCalendarList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterAutoscaleCalendar `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterAutoscaleCalendar{}, &ClusterAutoscaleCalendarList{})
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2025 infraflows team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterAutoscaleProfileSpec defines the reusable scaling settings of the profile.
+type ClusterAutoscaleProfileSpec struct {
+	// HPA is the HorizontalPodAutoscaler configuration of the workloads referencing the profile with the
+	// hpa.infraflow.co/profile annotation. The hpa.infraflow.co annotations of the workload override
+	// individual fields of the profile.
+	HPA HPASpec `json:"hpa"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=cap
+// +kubebuilder:printcolumn:name="MinReplicas",type=integer,JSONPath=`.spec.hpa.minReplicas`
+// +kubebuilder:printcolumn:name="MaxReplicas",type=integer,JSONPath=`.spec.hpa.maxReplicas`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ClusterAutoscaleProfile is the Schema for the clusterautoscaleprofiles API. It is a named set of
+// scaling settings shared by workloads across namespaces, e.g. "web-standard" or "batch-burst".
+type ClusterAutoscaleProfile struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ClusterAutoscaleProfileSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterAutoscaleProfileList contains a list of ClusterAutoscaleProfile.
+type ClusterAutoscaleProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterAutoscaleProfile `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterAutoscaleProfile{}, &ClusterAutoscaleProfileList{})
+}
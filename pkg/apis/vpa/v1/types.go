@@ -0,0 +1,223 @@
+/*
+Copyright 2025 infraflows team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// UpdateMode controls when autoscaler applies changes to the pod resources.
+type UpdateMode string
+
+const (
+	// UpdateModeOff means that autoscaler never changes Pod resources.
+	UpdateModeOff UpdateMode = "Off"
+	// UpdateModeInitial means that autoscaler only assigns resources on pod creation.
+	UpdateModeInitial UpdateMode = "Initial"
+	// UpdateModeRecreate means that autoscaler assigns resources on pod creation and
+	// additionally can update them during the lifetime of the pod by evicting it.
+	UpdateModeRecreate UpdateMode = "Recreate"
+	// UpdateModeAuto means that autoscaler assigns resources on pod creation and
+	// additionally can update them during the lifetime of the pod, using any available update method.
+	UpdateModeAuto UpdateMode = "Auto"
+)
+
+// ContainerScalingMode controls whether autoscaler is enabled for a specific container.
+type ContainerScalingMode string
+
+const (
+	// ContainerScalingModeAuto means autoscaling is enabled for a container.
+	ContainerScalingModeAuto ContainerScalingMode = "Auto"
+	// ContainerScalingModeOff means autoscaling is disabled for a container.
+	ContainerScalingModeOff ContainerScalingMode = "Off"
+)
+
+// ContainerControlledValues controls which resource value should be autoscaled.
+type ContainerControlledValues string
+
+const (
+	// ContainerControlledValuesRequestsAndLimits means resource request and limits are scaled automatically.
+	ContainerControlledValuesRequestsAndLimits ContainerControlledValues = "RequestsAndLimits"
+	// ContainerControlledValuesRequestsOnly means resource request is scaled automatically.
+	ContainerControlledValuesRequestsOnly ContainerControlledValues = "RequestsOnly"
+)
+
+// DefaultContainerResourcePolicy can be passed as ContainerResourcePolicy.ContainerName
+// to specify the default policy for all containers.
+const DefaultContainerResourcePolicy = "*"
+
+// VerticalPodAutoscalerSpec is the specification of the behavior of the autoscaler.
+type VerticalPodAutoscalerSpec struct {
+	// TargetRef points to the controller managing the set of pods for the autoscaler to control.
+	TargetRef *autoscalingv1.CrossVersionObjectReference `json:"targetRef"`
+
+	// UpdatePolicy describes the rules on how changes are applied to the pods.
+	// +optional
+	UpdatePolicy *PodUpdatePolicy `json:"updatePolicy,omitempty"`
+
+	// ResourcePolicy controls how the autoscaler computes recommended resources.
+	// +optional
+	ResourcePolicy *PodResourcePolicy `json:"resourcePolicy,omitempty"`
+
+	// Recommenders responsible for generating recommendation for this object.
+	// +optional
+	Recommenders []*VerticalPodAutoscalerRecommenderSelector `json:"recommenders,omitempty"`
+}
+
+// PodUpdatePolicy describes the rules on how changes are applied to the pods.
+type PodUpdatePolicy struct {
+	// Controls when autoscaler applies changes to the pod resources.
+	// +optional
+	UpdateMode *UpdateMode `json:"updateMode,omitempty"`
+
+	// Minimal number of replicas which need to be alive for Updater to attempt pod eviction.
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+}
+
+// PodResourcePolicy controls how autoscaler computes the recommended resources
+// for containers belonging to the pod.
+type PodResourcePolicy struct {
+	// Per-container resource policies.
+	// +optional
+	ContainerPolicies []ContainerResourcePolicy `json:"containerPolicies,omitempty"`
+}
+
+// ContainerResourcePolicy controls how autoscaler computes the recommended resources
+// for a specific container.
+type ContainerResourcePolicy struct {
+	// Name of the container or DefaultContainerResourcePolicy, in which
+	// case the policy is used by the containers that don't have their own policy specified.
+	ContainerName string `json:"containerName,omitempty"`
+
+	// Whether autoscaler is enabled for the container. The default is "Auto".
+	// +optional
+	Mode *ContainerScalingMode `json:"mode,omitempty"`
+
+	// Specifies the minimal amount of resources that will be recommended for the container.
+	// +optional
+	MinAllowed corev1.ResourceList `json:"minAllowed,omitempty"`
+
+	// Specifies the maximum amount of resources that will be recommended for the container.
+	// +optional
+	MaxAllowed corev1.ResourceList `json:"maxAllowed,omitempty"`
+
+	// Specifies the type of recommendations that will be computed
+	// (and possibly applied) by VPA.
+	// +optional
+	ControlledResources *[]corev1.ResourceName `json:"controlledResources,omitempty"`
+
+	// Specifies which resource values should be controlled.
+	// +optional
+	ControlledValues *ContainerControlledValues `json:"controlledValues,omitempty"`
+}
+
+// VerticalPodAutoscalerRecommenderSelector points to a specific Vertical Pod Autoscaler recommender.
+type VerticalPodAutoscalerRecommenderSelector struct {
+	// Name of the recommender responsible for generating recommendation for this object.
+	Name string `json:"name"`
+}
+
+// VerticalPodAutoscalerStatus describes the runtime state of the autoscaler.
+type VerticalPodAutoscalerStatus struct {
+	// The most recently computed amount of resources recommended by the autoscaler.
+	// +optional
+	Recommendation *RecommendedPodResources `json:"recommendation,omitempty"`
+
+	// Conditions is the set of conditions required for this autoscaler to scale its target.
+	// +optional
+	Conditions []VerticalPodAutoscalerCondition `json:"conditions,omitempty"`
+}
+
+// RecommendedPodResources is the recommendation of resources computed by autoscaler.
+type RecommendedPodResources struct {
+	// Resources recommended by the autoscaler for each container.
+	// +optional
+	ContainerRecommendations []RecommendedContainerResources `json:"containerRecommendations,omitempty"`
+}
+
+// RecommendedContainerResources is the recommendation of resources computed by
+// autoscaler for a specific container.
+type RecommendedContainerResources struct {
+	// Name of the container.
+	ContainerName string `json:"containerName,omitempty"`
+	// Recommended amount of resources.
+	Target corev1.ResourceList `json:"target"`
+	// Minimum recommended amount of resources.
+	// +optional
+	LowerBound corev1.ResourceList `json:"lowerBound,omitempty"`
+	// Maximum recommended amount of resources.
+	// +optional
+	UpperBound corev1.ResourceList `json:"upperBound,omitempty"`
+	// The most recent recommended resources target computed by the autoscaler
+	// for the controlled pods, based only on actual resource usage.
+	// +optional
+	UncappedTarget corev1.ResourceList `json:"uncappedTarget,omitempty"`
+}
+
+// VerticalPodAutoscalerCondition describes the state of a VerticalPodAutoscaler at a certain point.
+type VerticalPodAutoscalerCondition struct {
+	// Type describes the current condition.
+	Type string `json:"type"`
+	// Status is the status of the condition (True, False, Unknown).
+	Status corev1.ConditionStatus `json:"status"`
+	// Last time the condition transitioned from one status to another.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Reason is the reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Message is a human-readable explanation containing details about the transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=vpa
+
+// VerticalPodAutoscaler is the configuration for a vertical pod
+// autoscaler, which automatically manages pod resources based on historical and
+// real time resource utilization.
+type VerticalPodAutoscaler struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Specification of the behavior of the autoscaler.
+	Spec VerticalPodAutoscalerSpec `json:"spec"`
+
+	// Current information about the autoscaler.
+	// +optional
+	Status VerticalPodAutoscalerStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// VerticalPodAutoscalerList is a list of VerticalPodAutoscaler objects.
+type VerticalPodAutoscalerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	// items is the list of vertical pod autoscaler objects.
+	Items []VerticalPodAutoscaler `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VerticalPodAutoscaler{}, &VerticalPodAutoscalerList{})
+}
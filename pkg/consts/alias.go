@@ -0,0 +1,51 @@
+package consts
+
+import "sort"
+
+// Aliases maps legacy and previously documented annotation keys to their canonical key.
+// Aliased keys are still honored but are deprecated; see NormalizeAnnotations.
+var Aliases = map[string]string{
+	// Spellings documented in docs/annotations.md before the keys were unified.
+	hpaPrefix + "cpu.minReplicas": HPAMinReplicas,
+	hpaPrefix + "cpu.maxReplicas": HPAMaxReplicas,
+	vpaPrefix + "mode":            VPAUpdateMode,
+
+	// Kebab-case spellings used by early releases.
+	hpaPrefix + "min-replicas":                           HPAMinReplicas,
+	hpaPrefix + "max-replicas":                           HPAMaxReplicas,
+	"cpu." + hpaPrefix + "target-average-utilization":    HPACpuTargetAverageUtilization,
+	"cpu." + hpaPrefix + "target-average-value":          HPACpuTargetAverageValue,
+	"memory." + hpaPrefix + "target-average-utilization": HPAMemoryTargetAverageUtilization,
+	"memory." + hpaPrefix + "target-average-value":       HPAMemoryTargetAverageValue,
+	vpaPrefix + "update-mode":                            VPAUpdateMode,
+	vpaPrefix + "resource-policy":                        VPAResourcePolicy,
+}
+
+// Deprecation describes a deprecated annotation key found on a workload.
+type Deprecation struct {
+	// Key is the deprecated key set on the workload.
+	Key string
+	// Canonical is the key that should be used instead.
+	Canonical string
+}
+
+// NormalizeAnnotations returns a copy of the annotations where every aliased key is
+// rewritten to its canonical key, together with the deprecated keys that were found,
+// sorted by key. When both an alias and its canonical key are set, the canonical key wins.
+func NormalizeAnnotations(annotations map[string]string) (map[string]string, []Deprecation) {
+	normalized := make(map[string]string, len(annotations))
+	var deprecations []Deprecation
+	for key, val := range annotations {
+		canonical, ok := Aliases[key]
+		if !ok {
+			normalized[key] = val
+			continue
+		}
+		deprecations = append(deprecations, Deprecation{Key: key, Canonical: canonical})
+		if _, set := annotations[canonical]; !set {
+			normalized[canonical] = val
+		}
+	}
+	sort.Slice(deprecations, func(i, j int) bool { return deprecations[i].Key < deprecations[j].Key })
+	return normalized, deprecations
+}
@@ -1,9 +1,23 @@
 package consts
 
+import (
+	"strconv"
+	"strings"
+)
+
 // Private prefixes for annotations.
 const (
-	hpaPrefix = "hpa.infraflow.co/"
+	hpaDomain = "hpa.infraflow.co"
+	hpaPrefix = hpaDomain + "/"
 	vpaPrefix = "vpa.infraflow.co/"
+
+	statusPrefix    = "status.infraflow.co/"
+	autoscalePrefix = "autoscale.infraflow.co/"
+
+	prometheusPrefix = "prometheus." + hpaPrefix
+	podsPrefix       = "pods." + hpaPrefix
+	objectPrefix     = "object." + hpaPrefix
+	schedulePrefix   = "schedule." + hpaPrefix
 )
 
 // HPAMinReplicas defines the minimum number of replicas for the workload.
@@ -30,6 +44,167 @@ const HPAMemoryTargetAverageUtilization = hpaPrefix + "memory.targetAverageUtili
 // Value: string (memory size). Example: "512Mi".
 const HPAMemoryTargetAverageValue = hpaPrefix + "memory.targetAverageValue"
 
+// HPAAdopt allows the controller to take over a pre-existing HorizontalPodAutoscaler with the same
+// name that it did not create. Without it, such HPAs are left untouched and a conflict Event is recorded.
+// Value: string (bool). Example: "true".
+const HPAAdopt = hpaPrefix + "adopt"
+
+// HPANameTemplate overrides the --hpa-name-template of the controller for the HPA of the workload. The
+// template is a Go text/template with the fields .Name, .Namespace and .Kind of the workload and the
+// functions lower and upper, and must render a valid object name. When the name changes, the HPA with
+// the previous name is deleted.
+// Value: string (template). Example: "{{.Name}}-{{.Kind | lower}}-hpa".
+const HPANameTemplate = hpaPrefix + "nameTemplate"
+
+// HPAPaused freezes the HPA of the workload without removing its configuration: while set to "true",
+// the minReplicas and maxReplicas of the HPA are pinned to the replica count of the workload at the time
+// it was paused, and the configured values are restored once the annotation is removed or set to "false".
+// Value: string (bool). Example: "true".
+const HPAPaused = hpaPrefix + "paused"
+
+// HPACalendar references a ClusterAutoscaleCalendar whose active entry sets floors for the minReplicas
+// and maxReplicas of the HPA, applied after the schedule windows. It overrides spec.hpa.calendar
+// of the AutoscalePolicy or ClusterAutoscaleProfile.
+// Value: string (calendar name). Example: "retail-holidays".
+const HPACalendar = hpaPrefix + "calendar"
+
+// hpaOptions are HPA annotations which only control how the HPA is managed, on their own they do not
+// make the controller create a HorizontalPodAutoscaler.
+var hpaOptions = map[string]bool{
+	HPAAdopt:        true,
+	HPANameTemplate: true,
+	HPAPaused:       true,
+	HPACalendar:     true,
+}
+
+// IsHPAOption reports whether key is an HPA annotation which does not configure the HPA itself.
+func IsHPAOption(key string) bool {
+	return hpaOptions[key]
+}
+
+// HPAProfile references a ClusterAutoscaleProfile whose minReplicas, maxReplicas, metrics and behavior
+// are used for the HPA of the workload. The other HPA annotations of the workload override individual
+// fields of the profile.
+// Value: string (profile name). Example: "web-standard".
+const HPAProfile = hpaPrefix + "profile"
+
+// HPAScaleUpStabilizationWindowSeconds defines the number of seconds for which past recommendations
+// are considered while scaling up.
+// Value: string (seconds, 0-3600). Example: "0".
+const HPAScaleUpStabilizationWindowSeconds = hpaPrefix + "scaleUp.stabilizationWindowSeconds"
+
+// HPAScaleUpSelectPolicy defines which scale up policy is used when several policies are given.
+// Value: string. Allowed values: "Max", "Min", "Disabled".
+const HPAScaleUpSelectPolicy = hpaPrefix + "scaleUp.selectPolicy"
+
+// HPAScaleUpPolicies defines the list of policies that limit how fast the workload scales up.
+// Value: string (JSON-encoded list of HPAScalingPolicy).
+// Example: `[{"type":"Pods","value":4,"periodSeconds":60},{"type":"Percent","value":100,"periodSeconds":60}]`.
+const HPAScaleUpPolicies = hpaPrefix + "scaleUp.policies"
+
+// HPAScaleDownStabilizationWindowSeconds defines the number of seconds for which past recommendations
+// are considered while scaling down.
+// Value: string (seconds, 0-3600). Example: "300".
+const HPAScaleDownStabilizationWindowSeconds = hpaPrefix + "scaleDown.stabilizationWindowSeconds"
+
+// HPAScaleDownSelectPolicy defines which scale down policy is used when several policies are given.
+// Value: string. Allowed values: "Max", "Min", "Disabled".
+const HPAScaleDownSelectPolicy = hpaPrefix + "scaleDown.selectPolicy"
+
+// HPAScaleDownPolicies defines the list of policies that limit how fast the workload scales down.
+// Value: string (JSON-encoded list of HPAScalingPolicy).
+// Example: `[{"type":"Percent","value":10,"periodSeconds":60}]`.
+const HPAScaleDownPolicies = hpaPrefix + "scaleDown.policies"
+
+// HPAContainerPrefix is the prefix of per-container resource metric annotations, which scale on the
+// resource usage of a single container instead of the whole pod. The full key is built with ContainerKey.
+// Example: "hpa.infraflow.co/container.app.cpu.targetAverageUtilization".
+const HPAContainerPrefix = hpaPrefix + "container."
+
+// PrometheusMetricName defines the name of the Prometheus external metric used for HPA scaling.
+// Several external metrics can be configured with indexed keys, see IndexedKey.
+// Value: string. Example: "http_requests_total".
+const PrometheusMetricName = prometheusPrefix + "metricName"
+
+// PrometheusMetricSelector defines the label selector used to narrow down the external metric series.
+// Value: string (label selector). Example: "service=api,method in (GET,POST)".
+const PrometheusMetricSelector = prometheusPrefix + "metricSelector"
+
+// PrometheusTargetAverageValue defines the target value of the external metric per replica.
+// Value: string (quantity). Example: "100".
+const PrometheusTargetAverageValue = prometheusPrefix + "targetAverageValue"
+
+// PrometheusTargetValue defines the target value of the external metric as a whole.
+// Mutually exclusive with PrometheusTargetAverageValue.
+// Value: string (quantity). Example: "1k".
+const PrometheusTargetValue = prometheusPrefix + "targetValue"
+
+// PodsMetricName defines the name of a per-pod custom metric (custom.metrics.k8s.io) used for HPA scaling.
+// Several pods metrics can be configured with indexed keys, see IndexedKey.
+// Value: string. Example: "queue_depth".
+const PodsMetricName = podsPrefix + "metricName"
+
+// PodsMetricSelector defines the label selector used to narrow down the pods metric series.
+// Value: string (label selector). Example: "queue=orders".
+const PodsMetricSelector = podsPrefix + "metricSelector"
+
+// PodsTargetAverageValue defines the target value of the pods metric averaged across all pods.
+// Value: string (quantity). Example: "30".
+const PodsTargetAverageValue = podsPrefix + "targetAverageValue"
+
+// ObjectMetricName defines the name of a custom metric describing a single Kubernetes object
+// (e.g. requests-per-second of an Ingress). Several object metrics can be configured with indexed keys.
+// Value: string. Example: "requests_per_second".
+const ObjectMetricName = objectPrefix + "metricName"
+
+// ObjectMetricSelector defines the label selector used to narrow down the object metric series.
+// Value: string (label selector). Example: "path=/api".
+const ObjectMetricSelector = objectPrefix + "metricSelector"
+
+// ObjectTargetValue defines the target value of the object metric.
+// Value: string (quantity). Example: "2k".
+const ObjectTargetValue = objectPrefix + "targetValue"
+
+// ObjectTargetAverageValue defines the target value of the object metric divided by the number of pods.
+// Mutually exclusive with ObjectTargetValue.
+// Value: string (quantity). Example: "100".
+const ObjectTargetAverageValue = objectPrefix + "targetAverageValue"
+
+// ObjectDescribedObjectAPIVersion defines the API version of the object the metric describes.
+// Value: string. Example: "networking.k8s.io/v1".
+const ObjectDescribedObjectAPIVersion = objectPrefix + "describedObject.apiVersion"
+
+// ObjectDescribedObjectKind defines the kind of the object the metric describes.
+// Value: string. Example: "Ingress".
+const ObjectDescribedObjectKind = objectPrefix + "describedObject.kind"
+
+// ObjectDescribedObjectName defines the name of the object the metric describes.
+// The object must live in the same namespace as the workload.
+// Value: string. Example: "main-route".
+const ObjectDescribedObjectName = objectPrefix + "describedObject.name"
+
+// ScheduleCron defines the starts of a recurring window during which ScheduleMinReplicas and
+// ScheduleMaxReplicas override the replica limits of the HPA, as a standard 5-field cron expression.
+// Several windows can be configured with indexed keys, see IndexedKey; the first active one takes effect.
+// Value: string (cron expression). Example: "0 8 * * 1-5".
+const ScheduleCron = schedulePrefix + "cron"
+
+// ScheduleTimeZone defines the IANA time zone ScheduleCron is evaluated in. Defaults to UTC.
+// Value: string. Example: "Asia/Shanghai".
+const ScheduleTimeZone = schedulePrefix + "timeZone"
+
+// ScheduleDuration defines how long the window lasts after each start, a whole number of minutes.
+// Value: string (duration). Example: "10h".
+const ScheduleDuration = schedulePrefix + "duration"
+
+// ScheduleMinReplicas overrides the minimum number of replicas while the window is active.
+// Value: string. Example: "20".
+const ScheduleMinReplicas = schedulePrefix + "minReplicas"
+
+// ScheduleMaxReplicas overrides the maximum number of replicas while the window is active.
+// Value: string. Example: "50".
+const ScheduleMaxReplicas = schedulePrefix + "maxReplicas"
+
 // VPACpuMinAllowed defines the minimum allowed CPU (cores) for a container in VPA recommendations.
 // Value: string (CPU quantity). Example: "200m".
 const VPACpuMinAllowed = vpaPrefix + "cpu.minAllowed"
@@ -59,3 +234,86 @@ const VPAResourcePolicy = vpaPrefix + "resourcePolicy"
 const VPAContainerPolicy = vpaPrefix + "containerPolicies"
 
 const AutoScaleFinalizer = "finalizers.infraflow.co/autoscale"
+
+// StatusValidation is written by the controller on the workload when some of its annotations are
+// invalid, listing each invalid annotation and the reason. It is removed once all annotations are valid.
+// Value: string. Example: `invalid annotations: metadata.annotations[hpa.infraflow.co/maxReplicas]: Invalid value: "ten": must be an integer`.
+const StatusValidation = statusPrefix + "validation"
+
+// FieldManager is the field manager of the server-side apply requests writing HPAs and VPAs. Only the
+// fields set by the controller are owned by it, fields set by other managers are left alone.
+const FieldManager = "infraflow-autoscale"
+
+// StatusAutoscale is written by the controller on every workload it manages autoscalers for, as a JSON
+// object with the names of the managed HPA and VPA, the AutoscalePolicy or ClusterAutoscaleProfile they
+// are built from, why the HPA is paused if it is, the hash of the observed autoscale annotations, the last
+// reconcile time and the last error. It is only rewritten when its content other than the reconcile time
+// changes, and is removed once the workload is no longer autoscaled.
+// Example: `{"hpa":"web","observedAnnotationsHash":"3f2a9c1d0b7e4a56","lastReconcileTime":"2025-06-01T08:00:00Z"}`.
+const StatusAutoscale = statusPrefix + "autoscale"
+
+// DefaultsProfileLabel is set on a namespace to select the defaults profile whose annotations are
+// filled in on the Deployments created in that namespace, without overwriting explicit annotations.
+// Value: string (profile name). Example: "web-standard".
+const DefaultsProfileLabel = autoscalePrefix + "defaults"
+
+// DefaultsProfileAnnotation is written on a workload by the defaulting webhook and records the name
+// of the defaults profile that was applied to it.
+// Value: string (profile name). Example: "web-standard".
+const DefaultsProfileAnnotation = autoscalePrefix + "defaults-profile"
+
+// PausedLabel is set to "true" on a namespace to pause the HPAs of all workloads in it, like HPAPaused
+// on every workload.
+const PausedLabel = autoscalePrefix + "paused"
+
+// PausedReplicasAnnotation is set on a paused HPA to the replica count its minReplicas and maxReplicas
+// are pinned to. It keeps the pinned count stable across reconciles and is removed when the HPA is resumed.
+const PausedReplicasAnnotation = autoscalePrefix + "paused-replicas"
+
+// ManagedByLabel is the well-known label set on the autoscalers created or adopted by the controller.
+const ManagedByLabel = "app.kubernetes.io/managed-by"
+
+// ManagedByValue is the value of ManagedByLabel identifying this controller.
+const ManagedByValue = "infraflow-autoscale-controller"
+
+// WorkloadKindLabel is set on the generated HPAs and VPAs to the kind of the workload they scale.
+// Example: `kubectl get hpa -l autoscale.infraflow.co/workload-kind=StatefulSet`.
+const WorkloadKindLabel = autoscalePrefix + "workload-kind"
+
+// WorkloadNameLabel is set on the generated HPAs and VPAs to the name of the workload they scale. It is
+// omitted when the name is longer than a label value may be.
+const WorkloadNameLabel = autoscalePrefix + "workload-name"
+
+// SourceHashAnnotation is set on the generated HPAs and VPAs to the hash of the autoscale annotations
+// of the workload they were built from, it matches the observedAnnotationsHash of StatusAutoscale.
+const SourceHashAnnotation = autoscalePrefix + "source-hash"
+
+// IndexedKey returns the indexed form of a metric annotation key, which allows a workload to
+// declare several metrics of the same source. The unindexed key is equivalent to index 0.
+// Example: IndexedKey(PrometheusMetricName, 1) == "prometheus.hpa.infraflow.co/1.metricName".
+func IndexedKey(key string, index int) string {
+	prefix, field, _ := strings.Cut(key, "/")
+	return prefix + "/" + strconv.Itoa(index) + "." + field
+}
+
+// IsHPAAnnotation reports whether the annotation key belongs to the HPA configuration,
+// i.e. its prefix is hpa.infraflow.co or one of its subdomains (e.g. prometheus.hpa.infraflow.co,
+// pods.hpa.infraflow.co or object.hpa.infraflow.co).
+func IsHPAAnnotation(key string) bool {
+	prefix, _, ok := strings.Cut(key, "/")
+	return ok && (prefix == hpaDomain || strings.HasSuffix(prefix, "."+hpaDomain))
+}
+
+// IsVPAAnnotation reports whether the annotation key belongs to the VPA configuration.
+func IsVPAAnnotation(key string) bool {
+	return strings.HasPrefix(key, vpaPrefix)
+}
+
+// ContainerKey returns the per-container form of a resource metric annotation key
+// (HPACpuTargetAverageUtilization, HPACpuTargetAverageValue, HPAMemoryTargetAverageUtilization
+// or HPAMemoryTargetAverageValue) for the named container.
+// Example: ContainerKey("app", HPACpuTargetAverageUtilization) == "hpa.infraflow.co/container.app.cpu.targetAverageUtilization".
+func ContainerKey(container, key string) string {
+	_, field, _ := strings.Cut(key, "/")
+	return HPAContainerPrefix + container + "." + field
+}
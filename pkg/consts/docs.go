@@ -0,0 +1,145 @@
+package consts
+
+import (
+	"strconv"
+	"strings"
+)
+
+//go:generate go run ../../hack/annotations-doc -doc ../../docs/annotations.md
+
+// AnnotationDoc describes a supported annotation key. The registry below is the source of
+// truth for the annotation tables in docs/annotations.md, which are generated by
+// hack/annotations-doc and must not be edited by hand.
+type AnnotationDoc struct {
+	// Section is the name of the generated table the key is listed in.
+	Section string
+	// Key is the annotation key, or its pattern for indexed and per-container keys.
+	Key string
+	// Example is an example value.
+	Example string
+	// Description is the description shown in the docs (in the language of the docs).
+	Description string
+}
+
+// Sections of the generated annotation tables.
+const (
+	DocSectionHPA        = "hpa"
+	DocSectionContainer  = "container"
+	DocSectionBehavior   = "behavior"
+	DocSectionPrometheus = "prometheus"
+	DocSectionPods       = "pods"
+	DocSectionObject     = "object"
+	DocSectionSchedule   = "schedule"
+	DocSectionVPA        = "vpa"
+)
+
+// containerPattern is the placeholder used for the container name in the docs.
+const containerPattern = "<name>"
+
+// AnnotationDocs lists every supported annotation key, grouped by section in doc order.
+var AnnotationDocs = []AnnotationDoc{
+	{DocSectionHPA, HPAMinReplicas, `"2"`, "最小副本数"},
+	{DocSectionHPA, HPAMaxReplicas, `"10"`, "最大副本数（未引用 profile 时必填），不能小于最小副本数"},
+	{DocSectionHPA, HPACpuTargetAverageUtilization, `"70"`, "CPU 使用率目标（百分比 %）"},
+	{DocSectionHPA, HPACpuTargetAverageValue, `"500m"`, "CPU 使用量目标（核数）"},
+	{DocSectionHPA, HPAMemoryTargetAverageUtilization, `"75"`, "内存使用率目标（百分比 %）"},
+	{DocSectionHPA, HPAMemoryTargetAverageValue, `"512Mi"`, "内存使用量目标（字节数）"},
+	{DocSectionHPA, HPAProfile, `"web-standard"`, "引用的 ClusterAutoscaleProfile，其余 HPA 注解覆盖配置中的对应字段"},
+	{DocSectionHPA, HPAAdopt, `"true"`, "接管已存在的同名 HPA（非本 Controller 创建），默认不接管"},
+	{DocSectionHPA, HPANameTemplate, `"{{.Name}}-hpa"`, "HPA 名称模板，覆盖启动参数 `--hpa-name-template`，见 [HPA / VPA 名称](#hpa--vpa-名称)"},
+	{DocSectionHPA, HPAPaused, `"true"`, "暂停扩缩容，将 HPA 的最小、最大副本数固定为当前副本数，移除后恢复，见 [暂停自动扩缩容](#暂停自动扩缩容)"},
+	{DocSectionHPA, HPACalendar, `"retail-holidays"`, "引用的 ClusterAutoscaleCalendar，生效的条目为最小、最大副本数设置下限，见 [节假日日历](#节假日日历)"},
+
+	{DocSectionContainer, ContainerKey(containerPattern, HPACpuTargetAverageUtilization), `"70"`, "指定容器的 CPU 使用率目标（百分比 %）"},
+	{DocSectionContainer, ContainerKey(containerPattern, HPACpuTargetAverageValue), `"500m"`, "指定容器的 CPU 使用量目标（核数）"},
+	{DocSectionContainer, ContainerKey(containerPattern, HPAMemoryTargetAverageUtilization), `"75"`, "指定容器的内存使用率目标（百分比 %）"},
+	{DocSectionContainer, ContainerKey(containerPattern, HPAMemoryTargetAverageValue), `"512Mi"`, "指定容器的内存使用量目标（字节数）"},
+
+	{DocSectionBehavior, HPAScaleUpStabilizationWindowSeconds, `"0"`, "扩容稳定窗口（秒，0-3600）"},
+	{DocSectionBehavior, HPAScaleUpSelectPolicy, `"Max" , "Min" , "Disabled"`, "多个扩容策略同时存在时的选择方式"},
+	{DocSectionBehavior, HPAScaleUpPolicies, "`[{\"type\":\"Pods\",\"value\":4,\"periodSeconds\":60}]`", "扩容策略列表（HPAScalingPolicy JSON），type 可选 Pods / Percent，value 需大于0，periodSeconds 为 1-1800"},
+	{DocSectionBehavior, HPAScaleDownStabilizationWindowSeconds, `"300"`, "缩容稳定窗口（秒，0-3600）"},
+	{DocSectionBehavior, HPAScaleDownSelectPolicy, `"Max" , "Min" , "Disabled"`, "多个缩容策略同时存在时的选择方式"},
+	{DocSectionBehavior, HPAScaleDownPolicies, "`[{\"type\":\"Percent\",\"value\":10,\"periodSeconds\":60}]`", "缩容策略列表（HPAScalingPolicy JSON），type 可选 Pods / Percent，value 需大于0，periodSeconds 为 1-1800"},
+
+	{DocSectionPrometheus, PrometheusMetricName, `"http_requests_total"`, "Prometheus 指标名称"},
+	{DocSectionPrometheus, PrometheusMetricSelector, `"service=api,method in (GET,POST)"`, "指标标签选择器（Kubernetes label selector 语法），可选"},
+	{DocSectionPrometheus, PrometheusTargetAverageValue, `"100"`, "每副本平均目标值（AverageValue）"},
+	{DocSectionPrometheus, PrometheusTargetValue, `"1k"`, "指标整体目标值（Value），与 targetAverageValue 二选一"},
+
+	{DocSectionPods, PodsMetricName, `"queue_depth"`, "每个 Pod 上报的指标名称"},
+	{DocSectionPods, PodsMetricSelector, `"queue=orders"`, "指标标签选择器，可选"},
+	{DocSectionPods, PodsTargetAverageValue, `"30"`, "所有 Pod 的平均目标值"},
+
+	{DocSectionObject, ObjectMetricName, `"requests_per_second"`, "描述某个对象的指标名称"},
+	{DocSectionObject, ObjectMetricSelector, `"path=/api"`, "指标标签选择器，可选"},
+	{DocSectionObject, ObjectTargetValue, `"2k"`, "指标目标值（Value）"},
+	{DocSectionObject, ObjectTargetAverageValue, `"100"`, "指标值除以 Pod 数量后的目标值（AverageValue），与 targetValue 二选一"},
+	{DocSectionObject, ObjectDescribedObjectAPIVersion, `"networking.k8s.io/v1"`, "被描述对象的 API 版本"},
+	{DocSectionObject, ObjectDescribedObjectKind, `"Ingress"`, "被描述对象的类型（必填）"},
+	{DocSectionObject, ObjectDescribedObjectName, `"main-route"`, "被描述对象的名称（必填），需与工作负载位于同一命名空间"},
+
+	{DocSectionSchedule, ScheduleCron, `"0 8 * * 1-5"`, "窗口开始时间（标准 5 字段 cron 表达式：分 时 日 月 周）"},
+	{DocSectionSchedule, ScheduleTimeZone, `"Asia/Shanghai"`, "cron 表达式使用的时区（IANA 名称），默认为 UTC"},
+	{DocSectionSchedule, ScheduleDuration, `"10h"`, "每次开始后窗口持续的时间，必须是整数分钟"},
+	{DocSectionSchedule, ScheduleMinReplicas, `"20"`, "窗口内的最小副本数"},
+	{DocSectionSchedule, ScheduleMaxReplicas, `"50"`, "窗口内的最大副本数，与 minReplicas 至少配置一个"},
+
+	{DocSectionVPA, VPAUpdateMode, `"Auto" , "Initial" , "Off"`, "VPA 更新模式。Auto 表示自动调整，Initial 表示仅初始化时设置，Off 禁用更新"},
+	{DocSectionVPA, VPACpuMinAllowed, `"200m"`, "容器允许的最小 CPU 资源限制"},
+	{DocSectionVPA, VPACpuMaxAllowed, `"2"`, "容器允许的最大 CPU 资源限制"},
+	{DocSectionVPA, VPAMemoryMinAllowed, `"256Mi"`, "容器允许的最小内存资源限制"},
+	{DocSectionVPA, VPAMemoryMaxAllowed, `"4Gi"`, "容器允许的最大内存资源限制"},
+	{DocSectionVPA, VPAResourcePolicy, "`{ \"containerPolicies\": [...] }`", "PodResourcePolicy 配置，详细控制各容器的扩缩规则"},
+	{DocSectionVPA, VPAContainerPolicy, "`[{ \"containerName\": \"app\", \"minAllowed\": {\"cpu\": \"200m\"} }]`", "ContainerResourcePolicy 列表，独立配置单个容器的资源策略"},
+}
+
+// indexedPrefixes are the prefixes whose keys can be indexed, see IndexedKey.
+var indexedPrefixes = map[string]bool{
+	prometheusPrefix: true,
+	podsPrefix:       true,
+	objectPrefix:     true,
+	schedulePrefix:   true,
+}
+
+// knownKeys contains the exact keys of AnnotationDocs and Aliases, and containerFields the fields
+// allowed after the container name of per-container keys.
+var knownKeys, containerFields = func() (map[string]bool, map[string]bool) {
+	keys := map[string]bool{}
+	fields := map[string]bool{}
+	for _, d := range AnnotationDocs {
+		if d.Section == DocSectionContainer {
+			fields[strings.TrimPrefix(d.Key, HPAContainerPrefix+containerPattern+".")] = true
+			continue
+		}
+		keys[d.Key] = true
+	}
+	for alias := range Aliases {
+		keys[alias] = true
+	}
+	return keys, fields
+}()
+
+// IsKnownAnnotation reports whether the key is a supported annotation key: a key listed in
+// AnnotationDocs, its indexed or per-container form, or a deprecated alias.
+func IsKnownAnnotation(key string) bool {
+	if knownKeys[key] {
+		return true
+	}
+	if rest, ok := strings.CutPrefix(key, HPAContainerPrefix); ok {
+		container, field, ok := strings.Cut(rest, ".")
+		return ok && container != "" && containerFields[field]
+	}
+	prefix, rest, ok := strings.Cut(key, "/")
+	if !ok || !indexedPrefixes[prefix+"/"] {
+		return false
+	}
+	index, field, ok := strings.Cut(rest, ".")
+	if !ok {
+		return false
+	}
+	if i, err := strconv.Atoi(index); err != nil || i < 0 {
+		return false
+	}
+	return knownKeys[prefix+"/"+field]
+}
@@ -0,0 +1,72 @@
+package kube
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// indexedGroup 同一索引下的一组指标注解
+// fields 的 key 为去掉前缀和索引后的字段名，例如 metricName；keys 记录每个字段对应的原始注解 Key
+type indexedGroup struct {
+	index  int
+	fields map[string]string
+	keys   map[string]string
+}
+
+// key 返回字段对应的原始注解 Key，字段不存在时返回不带索引的注解 Key，用于报告缺少字段的错误
+func (g indexedGroup) key(prefix, field string) string {
+	if key, ok := g.keys[field]; ok {
+		return key
+	}
+	if g.index == 0 {
+		return prefix + field
+	}
+	return prefix + strconv.Itoa(g.index) + "." + field
+}
+
+// groupIndexed 按索引对指定前缀的注解进行分组，结果按索引升序排列
+// 支持两种形式：
+// - <prefix><field>: 等价于索引 0
+// - <prefix><index>.<field>: 指定索引，例如 prometheus.hpa.infraflow.co/1.metricName
+func groupIndexed(annotations map[string]string, prefix string) []indexedGroup {
+	groups := map[int]indexedGroup{}
+	for key, val := range annotations {
+		rest, ok := strings.CutPrefix(key, prefix)
+		if !ok {
+			continue
+		}
+		index := 0
+		if head, field, ok := strings.Cut(rest, "."); ok {
+			if i, err := strconv.Atoi(head); err == nil && i >= 0 {
+				index, rest = i, field
+			}
+		}
+		group, ok := groups[index]
+		if !ok {
+			group = indexedGroup{index: index, fields: map[string]string{}, keys: map[string]string{}}
+			groups[index] = group
+		}
+		group.fields[rest] = val
+		group.keys[rest] = key
+	}
+
+	result := make([]indexedGroup, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, group)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].index < result[j].index })
+	return result
+}
+
+// fieldOf 返回注解常量中的字段名，例如 prometheus.hpa.infraflow.co/metricName -> metricName
+func fieldOf(key string) string {
+	_, field, _ := strings.Cut(key, "/")
+	return field
+}
+
+// prefixOf 返回注解常量中的前缀（包含"/"），例如 prometheus.hpa.infraflow.co/metricName -> prometheus.hpa.infraflow.co/
+func prefixOf(key string) string {
+	prefix, _, _ := strings.Cut(key, "/")
+	return prefix + "/"
+}
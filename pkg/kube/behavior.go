@@ -0,0 +1,231 @@
+package kube
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+// maxStabilizationWindowSeconds 稳定窗口的上限，与kube-apiserver的校验规则一致
+const maxStabilizationWindowSeconds = 3600
+
+// maxScalingPolicyPeriodSeconds 扩缩策略周期的上限，与kube-apiserver的校验规则一致
+const maxScalingPolicyPeriodSeconds = 1800
+
+// scalingRuleKeys 描述一个扩缩方向（scaleUp/scaleDown）对应的注解
+type scalingRuleKeys struct {
+	stabilizationWindowSeconds string
+	selectPolicy               string
+	policies                   string
+}
+
+var (
+	scaleUpKeys = scalingRuleKeys{
+		stabilizationWindowSeconds: consts.HPAScaleUpStabilizationWindowSeconds,
+		selectPolicy:               consts.HPAScaleUpSelectPolicy,
+		policies:                   consts.HPAScaleUpPolicies,
+	}
+	scaleDownKeys = scalingRuleKeys{
+		stabilizationWindowSeconds: consts.HPAScaleDownStabilizationWindowSeconds,
+		selectPolicy:               consts.HPAScaleDownSelectPolicy,
+		policies:                   consts.HPAScaleDownPolicies,
+	}
+)
+
+// buildBehavior 根据工作负载的注解构建HPA的扩缩行为配置
+// 支持的注解：
+// - hpa.infraflow.co/scaleUp.stabilizationWindowSeconds: 扩容稳定窗口（秒，0-3600）
+// - hpa.infraflow.co/scaleUp.selectPolicy: 扩容策略选择（Max/Min/Disabled）
+// - hpa.infraflow.co/scaleUp.policies: 扩容策略列表（JSON格式）
+// - hpa.infraflow.co/scaleDown.*: 缩容对应配置
+// 如果没有任何行为相关的注解，返回nil，即使用Kubernetes默认行为
+// 注解的值不合法时将错误记录在p中
+func buildBehavior(p *annotationParser) *autoscalingv2.HorizontalPodAutoscalerBehavior {
+	scaleUp := buildScalingRules(p, scaleUpKeys)
+	scaleDown := buildScalingRules(p, scaleDownKeys)
+	if scaleUp == nil && scaleDown == nil {
+		return nil
+	}
+	return &autoscalingv2.HorizontalPodAutoscalerBehavior{
+		ScaleUp:   scaleUp,
+		ScaleDown: scaleDown,
+	}
+}
+
+// buildScalingRules 构建单个扩缩方向的规则，没有相关注解时返回nil
+func buildScalingRules(p *annotationParser, keys scalingRuleKeys) *autoscalingv2.HPAScalingRules {
+	var rules *autoscalingv2.HPAScalingRules
+	ensure := func() *autoscalingv2.HPAScalingRules {
+		if rules == nil {
+			rules = &autoscalingv2.HPAScalingRules{}
+		}
+		return rules
+	}
+
+	if seconds := p.int32(keys.stabilizationWindowSeconds, 0, maxStabilizationWindowSeconds); seconds != nil {
+		ensure().StabilizationWindowSeconds = seconds
+	}
+	if val, ok := p.annotations[keys.selectPolicy]; ok {
+		if policy, ok := parseSelectPolicy(val); ok {
+			ensure().SelectPolicy = &policy
+		} else {
+			p.invalid(keys.selectPolicy, val, "must be one of Max, Min, Disabled")
+		}
+	}
+	if val, ok := p.annotations[keys.policies]; ok {
+		var policies []autoscalingv2.HPAScalingPolicy
+		if err := json.Unmarshal([]byte(val), &policies); err != nil {
+			p.invalid(keys.policies, val, "must be a JSON list of HPAScalingPolicy: "+err.Error())
+		} else if reasons := invalidScalingPolicies(policies); len(reasons) > 0 {
+			for _, reason := range reasons {
+				p.invalid(keys.policies, val, reason)
+			}
+		} else {
+			ensure().Policies = policies
+		}
+	}
+	return rules
+}
+
+// invalidScalingPolicies 按kube-apiserver的规则校验扩缩策略，返回每个不合法的字段的原因
+func invalidScalingPolicies(policies []autoscalingv2.HPAScalingPolicy) []string {
+	var reasons []string
+	for i, policy := range policies {
+		if policy.Type != autoscalingv2.PodsScalingPolicy && policy.Type != autoscalingv2.PercentScalingPolicy {
+			reasons = append(reasons, fmt.Sprintf("[%d].type must be one of Pods, Percent", i))
+		}
+		if policy.Value <= 0 {
+			reasons = append(reasons, fmt.Sprintf("[%d].value must be greater than 0", i))
+		}
+		if policy.PeriodSeconds <= 0 || policy.PeriodSeconds > maxScalingPolicyPeriodSeconds {
+			reasons = append(reasons, fmt.Sprintf("[%d].periodSeconds must be between 1 and %d", i, maxScalingPolicyPeriodSeconds))
+		}
+	}
+	return reasons
+}
+
+// MergeBehavior 合并两个扩缩行为配置，按扩缩方向逐个字段使用override中已设置的值覆盖base
+func MergeBehavior(base, override *autoscalingv2.HorizontalPodAutoscalerBehavior) *autoscalingv2.HorizontalPodAutoscalerBehavior {
+	if override == nil {
+		return base.DeepCopy()
+	}
+	if base == nil {
+		return override.DeepCopy()
+	}
+	return &autoscalingv2.HorizontalPodAutoscalerBehavior{
+		ScaleUp:   mergeScalingRules(base.ScaleUp, override.ScaleUp),
+		ScaleDown: mergeScalingRules(base.ScaleDown, override.ScaleDown),
+	}
+}
+
+// mergeScalingRules 合并单个扩缩方向的规则
+func mergeScalingRules(base, override *autoscalingv2.HPAScalingRules) *autoscalingv2.HPAScalingRules {
+	if override == nil {
+		return base.DeepCopy()
+	}
+	if base == nil {
+		return override.DeepCopy()
+	}
+	out := base.DeepCopy()
+	if override.StabilizationWindowSeconds != nil {
+		out.StabilizationWindowSeconds = override.StabilizationWindowSeconds
+	}
+	if override.SelectPolicy != nil {
+		out.SelectPolicy = override.SelectPolicy
+	}
+	if override.Policies != nil {
+		out.Policies = override.Policies
+	}
+	return out
+}
+
+// parseSelectPolicy 解析扩缩策略选择
+func parseSelectPolicy(val string) (autoscalingv2.ScalingPolicySelect, bool) {
+	switch policy := autoscalingv2.ScalingPolicySelect(val); policy {
+	case autoscalingv2.MaxChangePolicySelect, autoscalingv2.MinChangePolicySelect, autoscalingv2.DisabledPolicySelect:
+		return policy, true
+	default:
+		return "", false
+	}
+}
+
+// 与 kube-apiserver 中 HPA v2 的默认值保持一致
+var (
+	defaultScaleUpStabilizationWindowSeconds int32 = 0
+	defaultSelectPolicy                            = autoscalingv2.MaxChangePolicySelect
+	defaultScaleUpPolicies                         = []autoscalingv2.HPAScalingPolicy{
+		{Type: autoscalingv2.PodsScalingPolicy, Value: 4, PeriodSeconds: 15},
+		{Type: autoscalingv2.PercentScalingPolicy, Value: 100, PeriodSeconds: 15},
+	}
+	defaultScaleDownPolicies = []autoscalingv2.HPAScalingPolicy{
+		{Type: autoscalingv2.PercentScalingPolicy, Value: 100, PeriodSeconds: 15},
+	}
+)
+
+// DefaultBehavior 按照kube-apiserver的规则为扩缩行为填充默认值
+// 仅当behavior不为nil时才会填充，与apiserver的行为一致
+func DefaultBehavior(behavior *autoscalingv2.HorizontalPodAutoscalerBehavior) *autoscalingv2.HorizontalPodAutoscalerBehavior {
+	if behavior == nil {
+		return nil
+	}
+	out := behavior.DeepCopy()
+	out.ScaleUp = defaultScalingRules(out.ScaleUp, &defaultScaleUpStabilizationWindowSeconds, defaultScaleUpPolicies)
+	out.ScaleDown = defaultScalingRules(out.ScaleDown, nil, defaultScaleDownPolicies)
+	return out
+}
+
+// defaultScalingRules 为单个扩缩方向填充默认值
+func defaultScalingRules(rules *autoscalingv2.HPAScalingRules, window *int32, policies []autoscalingv2.HPAScalingPolicy) *autoscalingv2.HPAScalingRules {
+	if rules == nil {
+		rules = &autoscalingv2.HPAScalingRules{}
+	}
+	if rules.StabilizationWindowSeconds == nil && window != nil {
+		w := *window
+		rules.StabilizationWindowSeconds = &w
+	}
+	if rules.SelectPolicy == nil {
+		p := defaultSelectPolicy
+		rules.SelectPolicy = &p
+	}
+	if rules.Policies == nil {
+		rules.Policies = append([]autoscalingv2.HPAScalingPolicy(nil), policies...)
+	}
+	return rules
+}
+
+// EqualBehavior 比较两个扩缩行为配置是否相等
+// 比较前会先填充apiserver的默认值，避免因默认值导致的重复更新
+func EqualBehavior(a, b *autoscalingv2.HorizontalPodAutoscalerBehavior) bool {
+	x, y := DefaultBehavior(a), DefaultBehavior(b)
+	if x == nil || y == nil {
+		return x == nil && y == nil
+	}
+	return equalScalingRules(x.ScaleUp, y.ScaleUp) && equalScalingRules(x.ScaleDown, y.ScaleDown)
+}
+
+// equalScalingRules 比较两个已填充默认值的扩缩规则是否相等
+// HPA 按 selectPolicy 在所有策略中选择，策略的顺序不影响扩缩行为，排序后再比较
+func equalScalingRules(a, b *autoscalingv2.HPAScalingRules) bool {
+	x, y := *a, *b
+	x.Policies, y.Policies = sortedPolicies(x.Policies), sortedPolicies(y.Policies)
+	return equality.Semantic.DeepEqual(x, y)
+}
+
+// sortedPolicies 返回按类型、周期和值排序后的扩缩策略副本
+func sortedPolicies(policies []autoscalingv2.HPAScalingPolicy) []autoscalingv2.HPAScalingPolicy {
+	out := append([]autoscalingv2.HPAScalingPolicy(nil), policies...)
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Type != out[j].Type {
+			return out[i].Type < out[j].Type
+		}
+		if out[i].PeriodSeconds != out[j].PeriodSeconds {
+			return out[i].PeriodSeconds < out[j].PeriodSeconds
+		}
+		return out[i].Value < out[j].Value
+	})
+	return out
+}
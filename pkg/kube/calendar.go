@@ -0,0 +1,126 @@
+package kube
+
+import (
+	"fmt"
+	"time"
+
+	autoscalev1alpha1 "github.com/infraflows/autoscale-controller/pkg/apis/autoscale/v1alpha1"
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// calendarDateLayout 日历条目中日期的格式
+const calendarDateLayout = "2006-01-02"
+
+// CalendarName 返回工作负载引用的 ClusterAutoscaleCalendar 名称，没有引用时返回空字符串
+// hpa.infraflow.co/calendar 注解优先，否则使用HPA配置（AutoscalePolicy 或 ClusterAutoscaleProfile）中的 calendar
+func CalendarName(workload client.Object, spec *autoscalev1alpha1.HPASpec) string {
+	if name := workload.GetAnnotations()[consts.HPACalendar]; name != "" {
+		return name
+	}
+	if spec == nil {
+		return ""
+	}
+	return spec.Calendar
+}
+
+// CalendarNotFoundError 返回引用的 ClusterAutoscaleCalendar 不存在时的校验错误
+// 与 ProfileNotFoundError 相同，创建该日历后工作负载会被重新协调，因此不重新入队；
+// 通过注解引用时错误指向注解，否则指向HPA配置中的 calendar 字段
+func CalendarNotFoundError(workload client.Object, name string) error {
+	path := field.NewPath("spec", "hpa", "calendar")
+	if workload.GetAnnotations()[consts.HPACalendar] != "" {
+		path = annotationPath(consts.HPACalendar)
+	}
+	return &ValidationError{Errors: field.ErrorList{field.NotFound(path, name)}}
+}
+
+// ActiveCalendarEntry 返回now时日历中生效的条目，以及下一次有条目开始或结束的时间
+// 条目从开始日期的0点生效，到结束日期的次日0点结束，使用日历的时区；多个条目同时生效时使用列表中的第一个
+// 没有生效的条目时返回nil，没有条目会再开始时返回的时间为零值；时区或日期不合法时返回错误
+func ActiveCalendarEntry(calendar *autoscalev1alpha1.ClusterAutoscaleCalendar, now time.Time) (*autoscalev1alpha1.CalendarEntry, time.Time, error) {
+	loc, err := time.LoadLocation(calendar.Spec.TimeZone)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("calendar %s: %w", calendar.Name, err)
+	}
+	var active *autoscalev1alpha1.CalendarEntry
+	var next time.Time
+	for i := range calendar.Spec.Entries {
+		entry := &calendar.Spec.Entries[i]
+		start, end, err := calendarEntryRange(entry, loc)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("calendar %s entry %q: %w", calendar.Name, entry.Name, err)
+		}
+		var boundary time.Time
+		switch {
+		case now.Before(start):
+			boundary = start
+		case now.Before(end):
+			boundary = end
+			if active == nil {
+				active = entry
+			}
+		}
+		if !boundary.IsZero() && (next.IsZero() || boundary.Before(next)) {
+			next = boundary
+		}
+	}
+	return active, next, nil
+}
+
+// ValidateCalendar 校验日历的时区和条目的日期，与 ActiveCalendarEntry 使用相同的规则
+// 日期格式、副本数范围等由CRD的校验规则检查，这里检查CRD无法校验的时区和不存在的日期（例如 2025-02-30）
+func ValidateCalendar(calendar *autoscalev1alpha1.ClusterAutoscaleCalendar) field.ErrorList {
+	path := field.NewPath("spec")
+	loc, err := time.LoadLocation(calendar.Spec.TimeZone)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path.Child("timeZone"), calendar.Spec.TimeZone,
+			"must be an IANA time zone, e.g. Asia/Shanghai")}
+	}
+	var errs field.ErrorList
+	for i := range calendar.Spec.Entries {
+		if _, _, err := calendarEntryRange(&calendar.Spec.Entries[i], loc); err != nil {
+			errs = append(errs, field.Invalid(path.Child("entries").Index(i), calendar.Spec.Entries[i].Name, err.Error()))
+		}
+	}
+	return errs
+}
+
+// calendarEntryRange 返回条目生效的时间范围 [start, end)
+func calendarEntryRange(entry *autoscalev1alpha1.CalendarEntry, loc *time.Location) (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation(calendarDateLayout, entry.Start, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("start must be a date in the format YYYY-MM-DD")
+	}
+	last, err := time.ParseInLocation(calendarDateLayout, entry.End, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("end must be a date in the format YYYY-MM-DD")
+	}
+	if last.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end must not be before start")
+	}
+	return start, time.Date(last.Year(), last.Month(), last.Day()+1, 0, 0, 0, 0, loc), nil
+}
+
+// ApplyCalendarEntry 返回应用日历条目后的HPA配置，entry为nil时返回原配置
+// spec 为应用定时窗口（见 ApplySchedule）后的配置，条目中的副本数是下限：
+// minReplicas、maxReplicas 分别取当前值与条目中的值的较大者，不会降低定时窗口或注解配置的容量；
+// 之后 maxReplicas 小于 minReplicas 时提高到 minReplicas
+func ApplyCalendarEntry(spec *autoscalev1alpha1.HPASpec, entry *autoscalev1alpha1.CalendarEntry) *autoscalev1alpha1.HPASpec {
+	if entry == nil {
+		return spec
+	}
+	result := spec.DeepCopy()
+	if entry.MinReplicas != nil && (result.MinReplicas == nil || *result.MinReplicas < *entry.MinReplicas) {
+		v := *entry.MinReplicas
+		result.MinReplicas = &v
+	}
+	if entry.MaxReplicas != nil && result.MaxReplicas < *entry.MaxReplicas {
+		result.MaxReplicas = *entry.MaxReplicas
+	}
+	if result.MinReplicas != nil && result.MaxReplicas < *result.MinReplicas {
+		result.MaxReplicas = *result.MinReplicas
+	}
+	return result
+}
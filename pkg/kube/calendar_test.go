@@ -0,0 +1,148 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"time"
+
+	autoscalev1alpha1 "github.com/infraflows/autoscale-controller/pkg/apis/autoscale/v1alpha1"
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Calendar", func() {
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+	at := func(layout string) time.Time {
+		t, err := time.ParseInLocation("2006-01-02 15:04", layout, shanghai)
+		Expect(err).ShouldNot(HaveOccurred())
+		return t
+	}
+	calendar := &autoscalev1alpha1.ClusterAutoscaleCalendar{
+		ObjectMeta: metav1.ObjectMeta{Name: "retail-holidays"},
+		Spec: autoscalev1alpha1.ClusterAutoscaleCalendarSpec{
+			TimeZone: "Asia/Shanghai",
+			Entries: []autoscalev1alpha1.CalendarEntry{
+				{Name: "black-friday", Start: "2025-11-28", End: "2025-12-01", MinReplicas: int32Ptr(30)},
+				{Name: "december", Start: "2025-12-01", End: "2025-12-31", MinReplicas: int32Ptr(10)},
+				{Name: "chinese-new-year", Start: "2026-02-15", End: "2026-02-23", MaxReplicas: int32Ptr(5)},
+			},
+		},
+	}
+
+	DescribeTable("finding the active entry",
+		func(now, active, next string) {
+			entry, boundary, err := ActiveCalendarEntry(calendar, at(now))
+			Expect(err).ShouldNot(HaveOccurred())
+			if active == "" {
+				Expect(entry).Should(BeNil())
+			} else {
+				Expect(entry).ShouldNot(BeNil())
+				Expect(entry.Name).Should(Equal(active))
+			}
+			if next == "" {
+				Expect(boundary.IsZero()).Should(BeTrue())
+				return
+			}
+			Expect(boundary).Should(BeTemporally("==", at(next)))
+		},
+		Entry("before the first entry", "2025-11-27 23:59", "", "2025-11-28 00:00"),
+		Entry("at the start of an entry", "2025-11-28 00:00", "black-friday", "2025-12-01 00:00"),
+		Entry("on the last day of an entry", "2025-12-01 23:59", "black-friday", "2025-12-02 00:00"),
+		Entry("with the first of overlapping entries", "2025-12-01 12:00", "black-friday", "2025-12-02 00:00"),
+		Entry("after an overlapping entry ends", "2025-12-02 00:00", "december", "2026-01-01 00:00"),
+		Entry("between entries", "2026-01-10 12:00", "", "2026-02-15 00:00"),
+		Entry("after the last entry", "2026-02-24 00:00", "", ""),
+	)
+
+	It("Should evaluate the dates in UTC without a time zone", func() {
+		utc := calendar.DeepCopy()
+		utc.Spec.TimeZone = ""
+		// 2025-11-28 07:00 CST 为 2025-11-27 23:00 UTC
+		entry, next, err := ActiveCalendarEntry(utc, at("2025-11-28 07:00"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(entry).Should(BeNil())
+		Expect(next).Should(BeTemporally("==", time.Date(2025, 11, 28, 0, 0, 0, 0, time.UTC)))
+	})
+
+	DescribeTable("rejecting invalid calendars",
+		func(update func(*autoscalev1alpha1.ClusterAutoscaleCalendar)) {
+			invalid := calendar.DeepCopy()
+			update(invalid)
+			_, _, err := ActiveCalendarEntry(invalid, at("2025-11-28 12:00"))
+			Expect(err).Should(HaveOccurred())
+		},
+		Entry("with an unknown time zone", func(c *autoscalev1alpha1.ClusterAutoscaleCalendar) { c.Spec.TimeZone = "Mars/Olympus" }),
+		Entry("with an invalid start", func(c *autoscalev1alpha1.ClusterAutoscaleCalendar) { c.Spec.Entries[1].Start = "2025-13-01" }),
+		Entry("with an invalid end", func(c *autoscalev1alpha1.ClusterAutoscaleCalendar) { c.Spec.Entries[1].End = "tomorrow" }),
+		Entry("with the end before the start", func(c *autoscalev1alpha1.ClusterAutoscaleCalendar) { c.Spec.Entries[1].End = "2025-11-30" }),
+	)
+
+	It("Should raise the replicas to the floors of the active entry", func() {
+		spec := &autoscalev1alpha1.HPASpec{MinReplicas: int32Ptr(3), MaxReplicas: 20}
+		Expect(ApplyCalendarEntry(spec, nil)).Should(Equal(spec))
+		result := ApplyCalendarEntry(spec, &calendar.Spec.Entries[0])
+		Expect(result.MinReplicas).Should(HaveValue(Equal(int32(30))))
+		Expect(result.MaxReplicas).Should(Equal(int32(30)))
+		result = ApplyCalendarEntry(spec, &calendar.Spec.Entries[2])
+		Expect(result.MinReplicas).Should(HaveValue(Equal(int32(3))))
+		Expect(result.MaxReplicas).Should(Equal(int32(20)))
+		result = ApplyCalendarEntry(&autoscalev1alpha1.HPASpec{MaxReplicas: 2}, &calendar.Spec.Entries[2])
+		Expect(result.MinReplicas).Should(BeNil())
+		Expect(result.MaxReplicas).Should(Equal(int32(5)))
+	})
+
+	DescribeTable("applying the entry floors over the active schedule window",
+		func(window *autoscalev1alpha1.ScheduleWindow, entry *autoscalev1alpha1.CalendarEntry, minReplicas, maxReplicas int32) {
+			spec := &autoscalev1alpha1.HPASpec{MinReplicas: int32Ptr(3), MaxReplicas: 30}
+			result := ApplyCalendarEntry(ApplySchedule(spec, window), entry)
+			Expect(result.MinReplicas).Should(HaveValue(Equal(minReplicas)))
+			Expect(result.MaxReplicas).Should(Equal(maxReplicas))
+		},
+		Entry("raising minReplicas of the window",
+			&autoscalev1alpha1.ScheduleWindow{MinReplicas: int32Ptr(20), MaxReplicas: int32Ptr(40)},
+			&autoscalev1alpha1.CalendarEntry{MinReplicas: int32Ptr(25)}, int32(25), int32(40)),
+		Entry("raising maxReplicas of the window",
+			&autoscalev1alpha1.ScheduleWindow{MinReplicas: int32Ptr(20)},
+			&autoscalev1alpha1.CalendarEntry{MaxReplicas: int32Ptr(50)}, int32(20), int32(50)),
+		Entry("keeping the window above the entry",
+			&autoscalev1alpha1.ScheduleWindow{MinReplicas: int32Ptr(30), MaxReplicas: int32Ptr(60)},
+			&autoscalev1alpha1.CalendarEntry{MinReplicas: int32Ptr(20), MaxReplicas: int32Ptr(40)}, int32(30), int32(60)),
+		Entry("keeping the window above maxReplicas of the entry",
+			&autoscalev1alpha1.ScheduleWindow{MinReplicas: int32Ptr(20)},
+			&autoscalev1alpha1.CalendarEntry{MaxReplicas: int32Ptr(5)}, int32(20), int32(30)),
+		Entry("raising maxReplicas of the window to minReplicas of the entry",
+			&autoscalev1alpha1.ScheduleWindow{MaxReplicas: int32Ptr(10)},
+			&autoscalev1alpha1.CalendarEntry{MinReplicas: int32Ptr(15)}, int32(15), int32(15)),
+		Entry("without an active window",
+			nil, &autoscalev1alpha1.CalendarEntry{MinReplicas: int32Ptr(10), MaxReplicas: int32Ptr(20)}, int32(10), int32(30)),
+	)
+
+	It("Should prefer the calendar annotation over the HPA spec", func() {
+		spec := &autoscalev1alpha1.HPASpec{Calendar: "from-policy"}
+		workload := &appsv1.Deployment{}
+		Expect(CalendarName(workload, nil)).Should(BeEmpty())
+		Expect(CalendarName(workload, spec)).Should(Equal("from-policy"))
+		Expect(CalendarNotFoundError(workload, "from-policy").Error()).Should(ContainSubstring("spec.hpa.calendar"))
+
+		workload.Annotations = map[string]string{consts.HPACalendar: "from-annotation"}
+		Expect(CalendarName(workload, spec)).Should(Equal("from-annotation"))
+		Expect(CalendarNotFoundError(workload, "from-annotation").Error()).Should(ContainSubstring(consts.HPACalendar))
+	})
+})
@@ -0,0 +1,22 @@
+package kube
+
+// Capabilities 工作负载类型支持的自动扩缩容能力
+type Capabilities struct {
+	// HPA 是否支持水平扩缩容，要求工作负载具有 scale 子资源
+	HPA bool
+	// VPA 是否支持垂直扩缩容
+	VPA bool
+}
+
+// workloadCapabilities 各工作负载类型支持的自动扩缩容能力
+// DaemonSet 的副本数由节点数量决定，没有 scale 子资源，HPA 无法对其扩缩容
+var workloadCapabilities = map[string]Capabilities{
+	KindDeployment:  {HPA: true, VPA: true},
+	KindStatefulSet: {HPA: true, VPA: true},
+	KindDaemonSet:   {HPA: false, VPA: true},
+}
+
+// CapabilitiesOf 返回工作负载类型支持的自动扩缩容能力，不支持的类型返回零值
+func CapabilitiesOf(kind string) Capabilities {
+	return workloadCapabilities[kind]
+}
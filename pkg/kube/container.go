@@ -0,0 +1,106 @@
+package kube
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// containerMetricFields 单容器资源指标支持的字段，与Pod级别的资源指标注解一一对应
+var containerMetricFields = []struct {
+	field       string
+	resource    corev1.ResourceName
+	utilization bool
+}{
+	{fieldOf(consts.HPACpuTargetAverageUtilization), corev1.ResourceCPU, true},
+	{fieldOf(consts.HPACpuTargetAverageValue), corev1.ResourceCPU, false},
+	{fieldOf(consts.HPAMemoryTargetAverageUtilization), corev1.ResourceMemory, true},
+	{fieldOf(consts.HPAMemoryTargetAverageValue), corev1.ResourceMemory, false},
+}
+
+// buildContainerMetrics 根据工作负载的注解构建单容器资源指标配置
+// 支持的注解：
+// - hpa.infraflow.co/container.<name>.cpu.targetAverageUtilization: 容器CPU利用率目标
+// - hpa.infraflow.co/container.<name>.cpu.targetAverageValue: 容器CPU使用量目标
+// - hpa.infraflow.co/container.<name>.memory.targetAverageUtilization: 容器内存利用率目标
+// - hpa.infraflow.co/container.<name>.memory.targetAverageValue: 容器内存使用量目标
+// 注解中引用的容器不存在于工作负载的Pod模板中或注解的值不合法时将错误记录在p中
+func buildContainerMetrics(p *annotationParser, template *corev1.PodTemplateSpec) []autoscalingv2.MetricSpec {
+	keys := make([]string, 0)
+	for key := range p.annotations {
+		if strings.HasPrefix(key, consts.HPAContainerPrefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var specs []autoscalingv2.MetricSpec
+	for _, key := range keys {
+		container, field, ok := strings.Cut(strings.TrimPrefix(key, consts.HPAContainerPrefix), ".")
+		if !ok || container == "" {
+			p.invalid(key, p.annotations[key], "must be in the form "+consts.HPAContainerPrefix+"<container>.<field>")
+			continue
+		}
+		if !HasContainer(template, container) {
+			p.invalid(key, p.annotations[key], fmt.Sprintf("container %q does not exist in the pod template", container))
+			continue
+		}
+		supported := false
+		for _, f := range containerMetricFields {
+			if f.field != field {
+				continue
+			}
+			supported = true
+			if f.utilization {
+				if target := p.int32(key, 1, math.MaxInt32); target != nil {
+					specs = append(specs, ContainerUtilizationMetric(container, f.resource, *target))
+				}
+			} else if quantity := p.quantity(key); quantity != nil {
+				specs = append(specs, ContainerValueMetric(container, f.resource, *quantity))
+			}
+		}
+		if !supported {
+			p.invalid(key, p.annotations[key], "unsupported container metric field "+strconv.Quote(field))
+		}
+	}
+	return specs
+}
+
+// ContainerUtilizationMetric 基于单个容器资源利用率的HPA指标配置
+// target: 目标利用率百分比
+func ContainerUtilizationMetric(container string, name corev1.ResourceName, target int32) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ContainerResourceMetricSourceType,
+		ContainerResource: &autoscalingv2.ContainerResourceMetricSource{
+			Name:      name,
+			Container: container,
+			Target: autoscalingv2.MetricTarget{
+				Type:               autoscalingv2.UtilizationMetricType,
+				AverageUtilization: &target,
+			},
+		},
+	}
+}
+
+// ContainerValueMetric 基于单个容器资源使用量的HPA指标配置
+// quantity: 目标使用量
+func ContainerValueMetric(container string, name corev1.ResourceName, quantity resource.Quantity) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ContainerResourceMetricSourceType,
+		ContainerResource: &autoscalingv2.ContainerResourceMetricSource{
+			Name:      name,
+			Container: container,
+			Target: autoscalingv2.MetricTarget{
+				Type:         autoscalingv2.AverageValueMetricType,
+				AverageValue: &quantity,
+			},
+		},
+	}
+}
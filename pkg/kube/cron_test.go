@@ -0,0 +1,77 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cron", func() {
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+	newYork, _ := time.LoadLocation("America/New_York")
+	at := func(loc *time.Location, layout string) time.Time {
+		t, err := time.ParseInLocation("2006-01-02 15:04", layout, loc)
+		Expect(err).ShouldNot(HaveOccurred())
+		return t
+	}
+
+	DescribeTable("finding the next run",
+		func(spec string, loc *time.Location, from, expected string) {
+			schedule, err := ParseCron(spec)
+			Expect(err).ShouldNot(HaveOccurred())
+			next := schedule.Next(at(loc, from))
+			if expected == "" {
+				Expect(next.IsZero()).Should(BeTrue())
+				return
+			}
+			Expect(next).Should(BeTemporally("==", at(loc, expected)))
+		},
+		// 2025-06-02 是周一
+		Entry("on a weekday", "0 8 * * 1-5", shanghai, "2025-06-02 07:30", "2025-06-02 08:00"),
+		Entry("excluding the start time", "0 8 * * 1-5", shanghai, "2025-06-02 08:00", "2025-06-03 08:00"),
+		Entry("skipping the weekend", "0 8 * * 1-5", shanghai, "2025-06-06 09:00", "2025-06-09 08:00"),
+		Entry("with day names", "30 22 * * FRI", shanghai, "2025-06-02 00:00", "2025-06-06 22:30"),
+		Entry("with Sunday as 7", "0 0 * * 7", shanghai, "2025-06-02 00:00", "2025-06-08 00:00"),
+		Entry("with a step", "*/20 * * * *", shanghai, "2025-06-02 10:41", "2025-06-02 11:00"),
+		Entry("with a range and a step", "0 9-17/4 * * *", shanghai, "2025-06-02 13:00", "2025-06-02 17:00"),
+		Entry("with a list", "0 12,18 * * *", shanghai, "2025-06-02 12:00", "2025-06-02 18:00"),
+		Entry("with a day of month or a day of week", "0 0 15 * MON", shanghai, "2025-06-10 00:00", "2025-06-15 00:00"),
+		Entry("with month names", "0 0 1 JAN,JUL *", shanghai, "2025-06-02 00:00", "2025-07-01 00:00"),
+		Entry("with a descriptor", "@daily", shanghai, "2025-06-02 10:00", "2025-06-03 00:00"),
+		Entry("across the end of the year", "0 0 1 1 *", shanghai, "2025-06-02 00:00", "2026-01-01 00:00"),
+		Entry("never", "0 0 30 2 *", shanghai, "2025-06-02 00:00", ""),
+		Entry("skipping the time which does not exist on DST start", "30 2 * * *", newYork, "2025-03-09 01:00", "2025-03-10 02:30"),
+		Entry("after DST start", "0 3 * * *", newYork, "2025-03-09 01:00", "2025-03-09 03:00"),
+	)
+
+	DescribeTable("rejecting invalid expressions",
+		func(spec string) {
+			_, err := ParseCron(spec)
+			Expect(err).Should(HaveOccurred())
+		},
+		Entry("with too few fields", "0 8 * *"),
+		Entry("with too many fields", "0 0 8 * * *"),
+		Entry("with an out of range value", "60 * * * *"),
+		Entry("with an unknown name", "0 0 * * MONDAY"),
+		Entry("with an inverted range", "0 17-9 * * *"),
+		Entry("with a zero step", "*/0 * * * *"),
+		Entry("with an empty field", "0 8 * * 1,"),
+	)
+})
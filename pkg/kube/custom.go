@@ -0,0 +1,105 @@
+package kube
+
+import (
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// buildPodsMetrics 根据工作负载的注解构建基于custom metrics API的Pods指标配置
+// 支持的注解（可通过 <index>. 前缀配置多个指标，见 consts.IndexedKey）：
+// - pods.hpa.infraflow.co/metricName: 指标名称
+// - pods.hpa.infraflow.co/metricSelector: 指标标签选择器
+// - pods.hpa.infraflow.co/targetAverageValue: 所有Pod的平均值目标
+// 缺少指标名称、目标值或注解的值不合法时将错误记录在p中
+func buildPodsMetrics(p *annotationParser) []autoscalingv2.MetricSpec {
+	var specs []autoscalingv2.MetricSpec
+	prefix := prefixOf(consts.PodsMetricName)
+	for _, group := range groupIndexed(p.annotations, prefix) {
+		name, ok := p.metricName(group, prefix, fieldOf(consts.PodsMetricName))
+		if !ok {
+			continue
+		}
+		selector, selectorOK := p.metricSelector(group.key(prefix, fieldOf(consts.PodsMetricSelector)))
+		// Pods 指标只支持 AverageValue 类型的目标
+		target, targetOK := p.valueTarget(group.key(prefix, fieldOf(consts.PodsTargetAverageValue)), "")
+		if !selectorOK || !targetOK {
+			continue
+		}
+		specs = append(specs, PodsMetric(name, selector, target))
+	}
+	return specs
+}
+
+// buildObjectMetrics 根据工作负载的注解构建基于custom metrics API的Object指标配置
+// 支持的注解（可通过 <index>. 前缀配置多个指标，见 consts.IndexedKey）：
+// - object.hpa.infraflow.co/metricName: 指标名称
+// - object.hpa.infraflow.co/metricSelector: 指标标签选择器
+// - object.hpa.infraflow.co/targetValue: 指标值目标
+// - object.hpa.infraflow.co/targetAverageValue: 指标值除以Pod数量后的目标
+// - object.hpa.infraflow.co/describedObject.apiVersion: 被描述对象的API版本
+// - object.hpa.infraflow.co/describedObject.kind: 被描述对象的类型
+// - object.hpa.infraflow.co/describedObject.name: 被描述对象的名称
+// 缺少指标名称、被描述对象、目标值或注解的值不合法时将错误记录在p中
+func buildObjectMetrics(p *annotationParser) []autoscalingv2.MetricSpec {
+	var specs []autoscalingv2.MetricSpec
+	prefix := prefixOf(consts.ObjectMetricName)
+	for _, group := range groupIndexed(p.annotations, prefix) {
+		name, ok := p.metricName(group, prefix, fieldOf(consts.ObjectMetricName))
+		if !ok {
+			continue
+		}
+		describedObject := autoscalingv2.CrossVersionObjectReference{
+			APIVersion: group.fields[fieldOf(consts.ObjectDescribedObjectAPIVersion)],
+			Kind:       group.fields[fieldOf(consts.ObjectDescribedObjectKind)],
+			Name:       group.fields[fieldOf(consts.ObjectDescribedObjectName)],
+		}
+		describedOK := true
+		if describedObject.Kind == "" {
+			p.required(group.key(prefix, fieldOf(consts.ObjectDescribedObjectKind)), "described object kind is required")
+			describedOK = false
+		}
+		if describedObject.Name == "" {
+			p.required(group.key(prefix, fieldOf(consts.ObjectDescribedObjectName)), "described object name is required")
+			describedOK = false
+		}
+		selector, selectorOK := p.metricSelector(group.key(prefix, fieldOf(consts.ObjectMetricSelector)))
+		target, targetOK := p.valueTarget(group.key(prefix, fieldOf(consts.ObjectTargetAverageValue)),
+			group.key(prefix, fieldOf(consts.ObjectTargetValue)))
+		if !describedOK || !selectorOK || !targetOK {
+			continue
+		}
+		specs = append(specs, ObjectMetric(name, selector, describedObject, target))
+	}
+	return specs
+}
+
+// PodsMetric 基于Pods自定义指标的HPA指标配置
+func PodsMetric(name string, selector *metav1.LabelSelector, target autoscalingv2.MetricTarget) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.PodsMetricSourceType,
+		Pods: &autoscalingv2.PodsMetricSource{
+			Metric: autoscalingv2.MetricIdentifier{
+				Name:     name,
+				Selector: selector,
+			},
+			Target: target,
+		},
+	}
+}
+
+// ObjectMetric 基于Object自定义指标的HPA指标配置
+func ObjectMetric(name string, selector *metav1.LabelSelector,
+	describedObject autoscalingv2.CrossVersionObjectReference, target autoscalingv2.MetricTarget) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ObjectMetricSourceType,
+		Object: &autoscalingv2.ObjectMetricSource{
+			DescribedObject: describedObject,
+			Metric: autoscalingv2.MetricIdentifier{
+				Name:     name,
+				Selector: selector,
+			},
+			Target: target,
+		},
+	}
+}
@@ -0,0 +1,81 @@
+package kube
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultsProfiles 默认注解配置，Key为配置名称，Value为该配置提供的默认注解
+type DefaultsProfiles map[string]map[string]string
+
+// LoadDefaultsProfiles 从YAML文件中加载默认注解配置，文件格式如下：
+//
+//	web-standard:
+//	  hpa.infraflow.co/minReplicas: "2"
+//	  hpa.infraflow.co/maxReplicas: "10"
+//	  hpa.infraflow.co/cpu.targetAverageUtilization: "70"
+//
+// 已废弃的注解会转换为对应的新注解，配置中只允许使用已知的 hpa.infraflow.co/* 与 vpa.infraflow.co/* 注解
+func LoadDefaultsProfiles(path string) (DefaultsProfiles, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read defaults profiles: %w", err)
+	}
+	profiles := DefaultsProfiles{}
+	if err := yaml.UnmarshalStrict(data, &profiles); err != nil {
+		return nil, fmt.Errorf("failed to parse defaults profiles %s: %w", path, err)
+	}
+	for name, annotations := range profiles {
+		for key := range annotations {
+			if !(consts.IsHPAAnnotation(key) || consts.IsVPAAnnotation(key)) || !consts.IsKnownAnnotation(key) {
+				return nil, fmt.Errorf("defaults profile %s: unknown annotation key %s", name, key)
+			}
+		}
+		profiles[name], _ = consts.NormalizeAnnotations(annotations)
+	}
+	return profiles, nil
+}
+
+// ApplyDefaults 将默认注解合并到工作负载的注解中，已显式配置的注解（包括已废弃的写法）不会被覆盖
+// 默认的最小/最大副本数与显式配置的最大/最小副本数冲突时，不使用该默认值
+// 返回合并后的注解，以及实际补充的注解Key（按字母排序）
+func ApplyDefaults(annotations, defaults map[string]string) (map[string]string, []string) {
+	explicit, _ := consts.NormalizeAnnotations(annotations)
+	result := make(map[string]string, len(annotations)+len(defaults))
+	for key, val := range annotations {
+		result[key] = val
+	}
+
+	var applied []string
+	for key, val := range defaults {
+		if _, ok := explicit[key]; ok {
+			continue
+		}
+		switch key {
+		case consts.HPAMinReplicas:
+			if replicasGreater(val, explicit[consts.HPAMaxReplicas]) {
+				continue
+			}
+		case consts.HPAMaxReplicas:
+			if replicasGreater(explicit[consts.HPAMinReplicas], val) {
+				continue
+			}
+		}
+		result[key] = val
+		applied = append(applied, key)
+	}
+	sort.Strings(applied)
+	return result, applied
+}
+
+// replicasGreater 判断副本数 a 是否大于 b，任一值不是合法的整数时返回 false
+func replicasGreater(a, b string) bool {
+	x, errA := strconv.ParseInt(a, 10, 32)
+	y, errB := strconv.ParseInt(b, 10, 32)
+	return errA == nil && errB == nil && x > y
+}
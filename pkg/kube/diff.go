@@ -0,0 +1,146 @@
+package kube
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strconv"
+
+	vpav1 "github.com/infraflows/autoscale-controller/pkg/apis/vpa/v1"
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DiffHPA 返回HPA从current更新为desired时发生变化的字段摘要，用于事件中说明更新内容，例如：
+//
+//	spec.minReplicas: 2 -> 3
+//	spec.metrics[Resource/cpu]: changed
+//	spec.behavior.scaleDown: changed
+//
+// 与 EqualHPA 相同，比较前会先填充apiserver的默认值，没有变化时返回nil
+func DiffHPA(current, desired *autoscalingv2.HorizontalPodAutoscaler) []string {
+	var diff []string
+	a, b := DefaultHPASpec(current.Spec), DefaultHPASpec(desired.Spec)
+	if a.ScaleTargetRef != b.ScaleTargetRef {
+		diff = append(diff, fmt.Sprintf("spec.scaleTargetRef: %s/%s -> %s/%s",
+			a.ScaleTargetRef.Kind, a.ScaleTargetRef.Name, b.ScaleTargetRef.Kind, b.ScaleTargetRef.Name))
+	}
+	if !EqualInt32Ptr(a.MinReplicas, b.MinReplicas) {
+		diff = append(diff, fmt.Sprintf("spec.minReplicas: %s -> %s", int32PtrString(a.MinReplicas), int32PtrString(b.MinReplicas)))
+	}
+	if a.MaxReplicas != b.MaxReplicas {
+		diff = append(diff, fmt.Sprintf("spec.maxReplicas: %d -> %d", a.MaxReplicas, b.MaxReplicas))
+	}
+	diff = append(diff, diffMetrics(a.Metrics, b.Metrics)...)
+
+	x, y := a.Behavior, b.Behavior
+	switch {
+	case x == nil && y == nil:
+	case x == nil:
+		diff = append(diff, "spec.behavior: added")
+	case y == nil:
+		diff = append(diff, "spec.behavior: removed")
+	default:
+		if !equalScalingRules(x.ScaleUp, y.ScaleUp) {
+			diff = append(diff, "spec.behavior.scaleUp: changed")
+		}
+		if !equalScalingRules(x.ScaleDown, y.ScaleDown) {
+			diff = append(diff, "spec.behavior.scaleDown: changed")
+		}
+	}
+	return diff
+}
+
+// diffMetrics 按指标类型和名称对应两个指标配置数组，返回新增、移除和变化的指标
+func diffMetrics(current, desired []autoscalingv2.MetricSpec) []string {
+	key := func(m autoscalingv2.MetricSpec) string {
+		return string(m.Type) + "/" + metricName(m)
+	}
+	old := make(map[string]autoscalingv2.MetricSpec, len(current))
+	for _, m := range current {
+		old[key(m)] = m
+	}
+
+	var diff []string
+	seen := make(map[string]bool, len(desired))
+	for _, m := range desired {
+		k := key(m)
+		seen[k] = true
+		if prev, ok := old[k]; !ok {
+			diff = append(diff, fmt.Sprintf("spec.metrics[%s]: added", k))
+		} else if !equality.Semantic.DeepEqual(prev, m) {
+			diff = append(diff, fmt.Sprintf("spec.metrics[%s]: changed", k))
+		}
+	}
+	for _, m := range current {
+		if k := key(m); !seen[k] {
+			diff = append(diff, fmt.Sprintf("spec.metrics[%s]: removed", k))
+		}
+	}
+	return diff
+}
+
+// DiffVPA 返回VPA从current更新为desired时发生变化的字段摘要，没有变化时返回nil
+func DiffVPA(current, desired *vpav1.VerticalPodAutoscaler) []string {
+	var diff []string
+	a, b := current.Spec, desired.Spec
+	if !equality.Semantic.DeepEqual(a.TargetRef, b.TargetRef) {
+		diff = append(diff, "spec.targetRef: changed")
+	}
+	if !equality.Semantic.DeepEqual(a.UpdatePolicy, b.UpdatePolicy) {
+		diff = append(diff, fmt.Sprintf("spec.updatePolicy.updateMode: %s -> %s", updateModeString(a.UpdatePolicy), updateModeString(b.UpdatePolicy)))
+	}
+	if !equality.Semantic.DeepEqual(a.ResourcePolicy, b.ResourcePolicy) {
+		diff = append(diff, "spec.resourcePolicy: changed")
+	}
+	return diff
+}
+
+// DiffMetadata 返回对象从current更新为desired时，desired中的标签和注解发生变化的摘要，例如：
+//
+//	metadata.labels[autoscale.infraflow.co/workload-kind]: added
+//
+// source-hash 注解随注解变化，变化的内容已体现在spec的摘要中，因此不包含在内
+func DiffMetadata(current, desired client.Object) []string {
+	diff := diffMap("metadata.labels", current.GetLabels(), desired.GetLabels())
+	annotations := maps.Clone(desired.GetAnnotations())
+	delete(annotations, consts.SourceHashAnnotation)
+	return append(diff, diffMap("metadata.annotations", current.GetAnnotations(), annotations)...)
+}
+
+// diffMap 按Key排序返回desired中新增或发生变化的键值对
+func diffMap(path string, current, desired map[string]string) []string {
+	keys := make([]string, 0, len(desired))
+	for key := range desired {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var diff []string
+	for _, key := range keys {
+		if prev, ok := current[key]; !ok {
+			diff = append(diff, fmt.Sprintf("%s[%s]: added", path, key))
+		} else if prev != desired[key] {
+			diff = append(diff, fmt.Sprintf("%s[%s]: %s -> %s", path, key, prev, desired[key]))
+		}
+	}
+	return diff
+}
+
+// int32PtrString 返回int32指针的字符串表示，nil时返回 <nil>
+func int32PtrString(v *int32) string {
+	if v == nil {
+		return "<nil>"
+	}
+	return strconv.Itoa(int(*v))
+}
+
+// updateModeString 返回VPA更新模式的字符串表示，未设置时返回 <nil>
+func updateModeString(policy *vpav1.PodUpdatePolicy) string {
+	if policy == nil || policy.UpdateMode == nil {
+		return "<nil>"
+	}
+	return string(*policy.UpdateMode)
+}
@@ -0,0 +1,80 @@
+package kube
+
+import (
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+	"github.com/infraflows/autoscale-controller/pkg/metrics"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// buildExternalMetrics 根据工作负载的注解构建Prometheus External Metrics指标配置
+// 支持的注解（可通过 <index>. 前缀配置多个指标，见 consts.IndexedKey）：
+// - prometheus.hpa.infraflow.co/metricName: 指标名称
+// - prometheus.hpa.infraflow.co/metricSelector: 指标标签选择器
+// - prometheus.hpa.infraflow.co/targetAverageValue: 每副本平均值目标
+// - prometheus.hpa.infraflow.co/targetValue: 指标整体值目标
+// 同时配置 targetAverageValue 和 targetValue 时以 targetAverageValue 为准
+// 缺少指标名称、目标值或注解的值不合法时将错误记录在p中
+func buildExternalMetrics(p *annotationParser) []autoscalingv2.MetricSpec {
+	var specs []autoscalingv2.MetricSpec
+	prefix := prefixOf(consts.PrometheusMetricName)
+	for _, group := range groupIndexed(p.annotations, prefix) {
+		name, ok := p.metricName(group, prefix, fieldOf(consts.PrometheusMetricName))
+		if !ok {
+			continue
+		}
+		selector, selectorOK := p.metricSelector(group.key(prefix, fieldOf(consts.PrometheusMetricSelector)))
+		target, targetOK := p.valueTarget(group.key(prefix, fieldOf(consts.PrometheusTargetAverageValue)),
+			group.key(prefix, fieldOf(consts.PrometheusTargetValue)))
+		if !selectorOK || !targetOK {
+			continue
+		}
+		specs = append(specs, metrics.PrometheusExternalMetric(name, selector, target))
+	}
+	return specs
+}
+
+// metricName 返回一组指标注解中的指标名称，名称为空时记录错误
+func (p *annotationParser) metricName(group indexedGroup, prefix, field string) (string, bool) {
+	name := group.fields[field]
+	if name == "" {
+		p.required(group.key(prefix, field), "metric name is required when other fields of the metric are set")
+		return "", false
+	}
+	return name, true
+}
+
+// metricSelector 解析指标标签选择器，注解不存在或为空时返回nil
+func (p *annotationParser) metricSelector(key string) (*metav1.LabelSelector, bool) {
+	val := p.annotations[key]
+	if val == "" {
+		return nil, true
+	}
+	selector, err := metav1.ParseToLabelSelector(val)
+	if err != nil {
+		p.invalid(key, val, "must be a label selector: "+err.Error())
+		return nil, false
+	}
+	return selector, true
+}
+
+// valueTarget 解析 AverageValue / Value 类型的指标目标，两者都存在时以 AverageValue 为准
+// valueKey 为空表示只支持 AverageValue 类型的目标
+func (p *annotationParser) valueTarget(averageValueKey, valueKey string) (autoscalingv2.MetricTarget, bool) {
+	if _, ok := p.annotations[averageValueKey]; ok {
+		quantity := p.quantity(averageValueKey)
+		if quantity == nil {
+			return autoscalingv2.MetricTarget{}, false
+		}
+		return metrics.AverageValueTarget(*quantity), true
+	}
+	if _, ok := p.annotations[valueKey]; ok && valueKey != "" {
+		quantity := p.quantity(valueKey)
+		if quantity == nil {
+			return autoscalingv2.MetricTarget{}, false
+		}
+		return metrics.ValueTarget(*quantity), true
+	}
+	p.required(averageValueKey, "metric target is required")
+	return autoscalingv2.MetricTarget{}, false
+}
@@ -1,78 +1,162 @@
 package kube
 
 import (
-	"strconv"
+	"fmt"
+	"math"
 
+	autoscalev1alpha1 "github.com/infraflows/autoscale-controller/pkg/apis/autoscale/v1alpha1"
 	"github.com/infraflows/autoscale-controller/pkg/consts"
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/resource"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// BuildDesiredHPA 根据工作负载的注解构建期望的Horizontal Pod autoscale配置
-// 支持的注解：
-// - hpa.infraflow.co/min-replicas: 最小副本数
-// - hpa.infraflow.co/max-replicas: 最大副本数
-// - cpu.hpa.infraflow.co/target-average-utilization: CPU利用率目标
-// - cpu.hpa.infraflow.co/target-average-value: CPU使用量目标
-// - memory.hpa.infraflow.co/target-average-utilization: 内存利用率目标
-// - memory.hpa.infraflow.co/target-average-value: 内存使用量目标
-func BuildDesiredHPA(workload client.Object, kind string) *autoscalingv2.HorizontalPodAutoscaler {
-	annotations := workload.GetAnnotations()
-	hpa := &autoscalingv2.HorizontalPodAutoscaler{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      workload.GetName(),
-			Namespace: workload.GetNamespace(),
-		},
+// BuildDesiredHPA 根据HPA配置构建工作负载期望的Horizontal Pod autoscale
+// 配置来自工作负载的注解（见 HPASpecFromAnnotations）或匹配的 AutoscalePolicy，name 见 HPAName，
+// 元数据见 AutoscalerObjectMeta
+// 工作负载类型不支持HPA（见 CapabilitiesOf）时返回错误
+func BuildDesiredHPA(workload client.Object, kind, name string, spec *autoscalev1alpha1.HPASpec) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	if !CapabilitiesOf(kind).HPA {
+		return nil, fmt.Errorf("%s does not support HorizontalPodAutoscaler", kind)
+	}
+	if spec == nil {
+		return nil, fmt.Errorf("no HorizontalPodAutoscaler is configured for %s %s", kind, workload.GetName())
+	}
+	spec = spec.DeepCopy()
+	metrics := spec.Metrics
+	if metrics == nil {
+		metrics = []autoscalingv2.MetricSpec{}
+	}
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: AutoscalerObjectMeta(workload, kind, name),
 		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
 			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
 				APIVersion: "apps/v1",
 				Kind:       kind,
 				Name:       workload.GetName(),
 			},
+			MinReplicas: spec.MinReplicas,
+			MaxReplicas: spec.MaxReplicas,
+			Metrics:     metrics,
+			Behavior:    spec.Behavior,
 		},
+	}, nil
+}
+
+// HPASpecFromAnnotations 根据工作负载的注解构建HPA配置
+// 支持的注解：
+// - hpa.infraflow.co/minReplicas: 最小副本数
+// - hpa.infraflow.co/maxReplicas: 最大副本数
+// - hpa.infraflow.co/cpu.targetAverageUtilization: CPU利用率目标
+// - hpa.infraflow.co/cpu.targetAverageValue: CPU使用量目标
+// - hpa.infraflow.co/memory.targetAverageUtilization: 内存利用率目标
+// - hpa.infraflow.co/memory.targetAverageValue: 内存使用量目标
+// - hpa.infraflow.co/container.<name>.*: 单容器资源指标，见 buildContainerMetrics
+// - prometheus.hpa.infraflow.co/*: Prometheus External Metrics，见 buildExternalMetrics
+// - pods.hpa.infraflow.co/*: Pods 自定义指标，见 buildPodsMetrics
+// - object.hpa.infraflow.co/*: Object 自定义指标，见 buildObjectMetrics
+// - hpa.infraflow.co/scaleUp.*, hpa.infraflow.co/scaleDown.*: 扩缩行为，见 buildBehavior
+// - schedule.hpa.infraflow.co/*: 定时窗口，替换profile中的窗口，见 buildSchedules
+// profile为工作负载通过 hpa.infraflow.co/profile 引用的 ClusterAutoscaleProfile 中的HPA配置，没有引用时为nil
+// 引用了profile时以profile为基础，注解覆盖其中的对应字段：
+// - minReplicas / maxReplicas: 直接覆盖
+// - 指标: profile中与注解指标类型和名称相同的指标被替换，其余追加，见 MergeMetrics
+// - 扩缩行为: 按扩缩方向逐个字段覆盖，见 MergeBehavior
+// 已废弃的注解（见 consts.Aliases）会先转换为对应的新注解
+// 没有profile且缺少 maxReplicas、注解的值不合法或单容器资源指标引用的容器不存在时返回 *ValidationError，
+// 其中包含所有不合法的注解
+func HPASpecFromAnnotations(workload client.Object, profile *autoscalev1alpha1.HPASpec) (*autoscalev1alpha1.HPASpec, error) {
+	annotations, _ := consts.NormalizeAnnotations(workload.GetAnnotations())
+	spec := &autoscalev1alpha1.HPASpec{}
+	if profile != nil {
+		spec = profile.DeepCopy()
 	}
 
-	if val, ok := annotations[consts.HPAMinReplicas]; ok {
-		if v, err := strconv.Atoi(val); err == nil {
-			min := int32(v)
-			hpa.Spec.MinReplicas = &min
-		}
+	p := &annotationParser{annotations: annotations}
+	if min := p.int32(consts.HPAMinReplicas, 1, math.MaxInt32); min != nil {
+		spec.MinReplicas = min
 	}
-	if val, ok := annotations[consts.HPAMaxReplicas]; ok {
-		if v, err := strconv.Atoi(val); err == nil {
-			hpa.Spec.MaxReplicas = int32(v)
+	_, hasMax := annotations[consts.HPAMaxReplicas]
+	max := p.int32(consts.HPAMaxReplicas, 1, math.MaxInt32)
+	switch {
+	case max != nil:
+		spec.MaxReplicas = *max
+	case !hasMax && profile == nil:
+		p.required(consts.HPAMaxReplicas, "maxReplicas is required")
+	}
+	if min := spec.MinReplicas; min != nil && (max != nil || !hasMax) && spec.MaxReplicas > 0 && *min > spec.MaxReplicas {
+		if max != nil {
+			p.invalid(consts.HPAMaxReplicas, annotations[consts.HPAMaxReplicas],
+				"must be greater than or equal to "+consts.HPAMinReplicas)
+		} else {
+			p.invalid(consts.HPAMinReplicas, annotations[consts.HPAMinReplicas],
+				fmt.Sprintf("must be less than or equal to maxReplicas %d of the profile", spec.MaxReplicas))
 		}
 	}
 
 	metrics := []autoscalingv2.MetricSpec{}
-	if val, ok := annotations[consts.HPACpuTargetAverageUtilization]; ok {
-		if target, err := strconv.Atoi(val); err == nil {
-			t := int32(target)
-			metrics = append(metrics, CPUUtilizationMetric(t))
-		}
+	if target := p.int32(consts.HPACpuTargetAverageUtilization, 1, math.MaxInt32); target != nil {
+		metrics = append(metrics, CPUUtilizationMetric(*target))
 	}
-	if val, ok := annotations[consts.HPACpuTargetAverageValue]; ok {
-		if quantity, err := resource.ParseQuantity(val); err == nil {
-			metrics = append(metrics, CPUValueMetric(quantity))
-		}
+	if quantity := p.quantity(consts.HPACpuTargetAverageValue); quantity != nil {
+		metrics = append(metrics, CPUValueMetric(*quantity))
+	}
+	if target := p.int32(consts.HPAMemoryTargetAverageUtilization, 1, math.MaxInt32); target != nil {
+		metrics = append(metrics, MemoryUtilizationMetric(*target))
+	}
+	if quantity := p.quantity(consts.HPAMemoryTargetAverageValue); quantity != nil {
+		metrics = append(metrics, MemoryValueMetric(*quantity))
 	}
-	if val, ok := annotations[consts.HPAMemoryTargetAverageUtilization]; ok {
-		if target, err := strconv.Atoi(val); err == nil {
-			t := int32(target)
-			metrics = append(metrics, MemoryUtilizationMetric(t))
+	metrics = append(metrics, buildContainerMetrics(p, PodTemplateOf(workload))...)
+	metrics = append(metrics, buildExternalMetrics(p)...)
+	metrics = append(metrics, buildPodsMetrics(p)...)
+	metrics = append(metrics, buildObjectMetrics(p)...)
+
+	spec.Metrics = MergeMetrics(spec.Metrics, metrics)
+	spec.Behavior = MergeBehavior(spec.Behavior, buildBehavior(p))
+	// 注解中配置了定时窗口时替换配置中的窗口
+	if schedules := buildSchedules(p); len(schedules) > 0 {
+		spec.Schedules = schedules
+	}
+	if err := toError(p.errs); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// MergeMetrics 合并两组指标配置，overrides中的指标替换base中类型和名称（见 metricName）相同的所有指标，
+// 放在base中第一个同名指标的位置，其余指标追加到末尾
+// overrides中的指标总是全部保留，例如同时配置了CPU利用率和CPU使用量，或多个名称相同、选择器不同的External指标
+func MergeMetrics(base, overrides []autoscalingv2.MetricSpec) []autoscalingv2.MetricSpec {
+	key := func(m autoscalingv2.MetricSpec) string {
+		return string(m.Type) + "/" + metricName(m)
+	}
+	byKey := map[string][]autoscalingv2.MetricSpec{}
+	for _, m := range overrides {
+		byKey[key(m)] = append(byKey[key(m)], m)
+	}
+
+	result := make([]autoscalingv2.MetricSpec, 0, len(base)+len(overrides))
+	placed := map[string]bool{}
+	for _, m := range base {
+		k := key(m)
+		if _, ok := byKey[k]; !ok {
+			result = append(result, *m.DeepCopy())
+			continue
+		}
+		if !placed[k] {
+			result = append(result, byKey[k]...)
+			placed[k] = true
 		}
 	}
-	if val, ok := annotations[consts.HPAMemoryTargetAverageValue]; ok {
-		if quantity, err := resource.ParseQuantity(val); err == nil {
-			metrics = append(metrics, MemoryValueMetric(quantity))
+	for _, m := range overrides {
+		if !placed[key(m)] {
+			result = append(result, m)
 		}
 	}
-
-	hpa.Spec.Metrics = metrics
-	return hpa
+	return result
 }
 
 // CPUUtilizationMetric 基于CPU利用率的HPA指标配置
@@ -135,17 +219,49 @@ func MemoryValueMetric(quantity resource.Quantity) autoscalingv2.MetricSpec {
 	}
 }
 
-// EqualHPA 比较两个HPA配置是否相等
-// 比较内容包括：
-// - 目标引用
-// - 指标配置
-// - 最小副本数
-// - 最大副本数
+// 与 kube-apiserver 中 HPA v2 的默认值保持一致
+var (
+	defaultMinReplicas    int32 = 1
+	defaultCPUUtilization int32 = 80
+)
+
+// DefaultHPASpec 按照kube-apiserver的规则为HPA配置填充默认值，返回填充后的副本
+// - 未设置 minReplicas 时为1
+// - 未配置指标时使用CPU平均利用率80%
+// - 扩缩行为的默认值见 DefaultBehavior
+func DefaultHPASpec(spec autoscalingv2.HorizontalPodAutoscalerSpec) autoscalingv2.HorizontalPodAutoscalerSpec {
+	out := *spec.DeepCopy()
+	if out.MinReplicas == nil {
+		minReplicas := defaultMinReplicas
+		out.MinReplicas = &minReplicas
+	}
+	if len(out.Metrics) == 0 {
+		utilization := defaultCPUUtilization
+		out.Metrics = []autoscalingv2.MetricSpec{{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{
+				Name: corev1.ResourceCPU,
+				Target: autoscalingv2.MetricTarget{
+					Type:               autoscalingv2.UtilizationMetricType,
+					AverageUtilization: &utilization,
+				},
+			},
+		}}
+	}
+	out.Behavior = DefaultBehavior(out.Behavior)
+	return out
+}
+
+// EqualHPA 比较两个HPA的spec是否语义相等
+// 比较前会先填充apiserver的默认值（见 DefaultHPASpec），避免因默认值导致的重复更新；
+// 指标和扩缩策略的顺序不影响比较结果
 func EqualHPA(a, b *autoscalingv2.HorizontalPodAutoscaler) bool {
-	return a.Spec.ScaleTargetRef == b.Spec.ScaleTargetRef &&
-		EqualMetrics(a.Spec.Metrics, b.Spec.Metrics) &&
-		EqualInt32Ptr(a.Spec.MinReplicas, b.Spec.MinReplicas) &&
-		a.Spec.MaxReplicas == b.Spec.MaxReplicas
+	x, y := DefaultHPASpec(a.Spec), DefaultHPASpec(b.Spec)
+	return x.ScaleTargetRef == y.ScaleTargetRef &&
+		EqualInt32Ptr(x.MinReplicas, y.MinReplicas) &&
+		x.MaxReplicas == y.MaxReplicas &&
+		EqualMetrics(x.Metrics, y.Metrics) &&
+		EqualBehavior(x.Behavior, y.Behavior)
 }
 
 // EqualInt32Ptr 比较两个int32指针是否相等
@@ -159,18 +275,42 @@ func EqualInt32Ptr(a, b *int32) bool {
 	return false
 }
 
-// EqualMetrics 比较两个指标配置数组是否相等
-// 比较内容包括：
-// - 指标类型
-// - 资源名称
+// EqualMetrics 比较两个指标配置数组是否语义相等，不考虑指标的顺序
+// 比较指标的所有字段，包括指标类型、资源或指标名称、标签选择器、Object 指标描述的对象、容器名称，
+// 以及目标的类型、平均利用率、平均值和值，Quantity 按数值比较（例如 500m 与 0.5 相等）
 func EqualMetrics(a, b []autoscalingv2.MetricSpec) bool {
 	if len(a) != len(b) {
 		return false
 	}
+	matched := make([]bool, len(b))
 	for i := range a {
-		if a[i].Type != b[i].Type || a[i].Resource.Name != b[i].Resource.Name {
+		found := false
+		for j := range b {
+			if !matched[j] && equality.Semantic.DeepEqual(a[i], b[j]) {
+				matched[j], found = true, true
+				break
+			}
+		}
+		if !found {
 			return false
 		}
 	}
 	return true
 }
+
+// metricName 返回指标的名称
+func metricName(m autoscalingv2.MetricSpec) string {
+	switch {
+	case m.Resource != nil:
+		return string(m.Resource.Name)
+	case m.ContainerResource != nil:
+		return m.ContainerResource.Container + "/" + string(m.ContainerResource.Name)
+	case m.External != nil:
+		return m.External.Metric.Name
+	case m.Pods != nil:
+		return m.Pods.Metric.Name
+	case m.Object != nil:
+		return m.Object.Metric.Name
+	}
+	return ""
+}
@@ -0,0 +1,326 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func int32Ptr(v int32) *int32 {
+	return &v
+}
+
+func quantityPtr(value string) *resource.Quantity {
+	q := resource.MustParse(value)
+	return &q
+}
+
+func resourceMetric(name corev1.ResourceName, utilization int32) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{
+			Name:   name,
+			Target: autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: int32Ptr(utilization)},
+		},
+	}
+}
+
+func containerMetric(container string, utilization int32) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ContainerResourceMetricSourceType,
+		ContainerResource: &autoscalingv2.ContainerResourceMetricSource{
+			Name:      corev1.ResourceCPU,
+			Container: container,
+			Target:    autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: int32Ptr(utilization)},
+		},
+	}
+}
+
+func externalMetric(name, value string, selector map[string]string) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ExternalMetricSourceType,
+		External: &autoscalingv2.ExternalMetricSource{
+			Metric: autoscalingv2.MetricIdentifier{Name: name, Selector: &metav1.LabelSelector{MatchLabels: selector}},
+			Target: autoscalingv2.MetricTarget{Type: autoscalingv2.AverageValueMetricType, AverageValue: quantityPtr(value)},
+		},
+	}
+}
+
+func podsMetric(name, value string) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.PodsMetricSourceType,
+		Pods: &autoscalingv2.PodsMetricSource{
+			Metric: autoscalingv2.MetricIdentifier{Name: name},
+			Target: autoscalingv2.MetricTarget{Type: autoscalingv2.AverageValueMetricType, AverageValue: quantityPtr(value)},
+		},
+	}
+}
+
+func objectMetric(name, ingress, value string) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ObjectMetricSourceType,
+		Object: &autoscalingv2.ObjectMetricSource{
+			DescribedObject: autoscalingv2.CrossVersionObjectReference{APIVersion: "networking.k8s.io/v1", Kind: "Ingress", Name: ingress},
+			Metric:          autoscalingv2.MetricIdentifier{Name: name},
+			Target:          autoscalingv2.MetricTarget{Type: autoscalingv2.ValueMetricType, Value: quantityPtr(value)},
+		},
+	}
+}
+
+func newHPA(mutate func(spec *autoscalingv2.HorizontalPodAutoscalerSpec)) *autoscalingv2.HorizontalPodAutoscaler {
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+			MinReplicas:    int32Ptr(2),
+			MaxReplicas:    10,
+			Metrics:        []autoscalingv2.MetricSpec{resourceMetric(corev1.ResourceCPU, 80)},
+		},
+	}
+	if mutate != nil {
+		mutate(&hpa.Spec)
+	}
+	return hpa
+}
+
+var _ = Describe("EqualHPA", func() {
+	DescribeTable("comparing HPA specs",
+		func(a, b func(spec *autoscalingv2.HorizontalPodAutoscalerSpec), equal bool) {
+			Expect(EqualHPA(newHPA(a), newHPA(b))).Should(Equal(equal))
+			Expect(EqualHPA(newHPA(b), newHPA(a))).Should(Equal(equal))
+		},
+		Entry("identical specs", nil, nil, true),
+		Entry("different scale target", nil, func(spec *autoscalingv2.HorizontalPodAutoscalerSpec) {
+			spec.ScaleTargetRef.Name = "api"
+		}, false),
+		Entry("different maxReplicas", nil, func(spec *autoscalingv2.HorizontalPodAutoscalerSpec) {
+			spec.MaxReplicas = 20
+		}, false),
+		Entry("different minReplicas", nil, func(spec *autoscalingv2.HorizontalPodAutoscalerSpec) {
+			spec.MinReplicas = int32Ptr(3)
+		}, false),
+		Entry("unset minReplicas equals the default of 1", func(spec *autoscalingv2.HorizontalPodAutoscalerSpec) {
+			spec.MinReplicas = nil
+		}, func(spec *autoscalingv2.HorizontalPodAutoscalerSpec) {
+			spec.MinReplicas = int32Ptr(1)
+		}, true),
+		Entry("empty metrics equal the default CPU utilization of 80", func(spec *autoscalingv2.HorizontalPodAutoscalerSpec) {
+			spec.Metrics = nil
+		}, nil, true),
+		Entry("empty metrics differ from another CPU utilization", func(spec *autoscalingv2.HorizontalPodAutoscalerSpec) {
+			spec.Metrics = []autoscalingv2.MetricSpec{}
+		}, func(spec *autoscalingv2.HorizontalPodAutoscalerSpec) {
+			spec.Metrics = []autoscalingv2.MetricSpec{resourceMetric(corev1.ResourceCPU, 60)}
+		}, false),
+		Entry("no behavior differs from an empty behavior", nil, func(spec *autoscalingv2.HorizontalPodAutoscalerSpec) {
+			spec.Behavior = &autoscalingv2.HorizontalPodAutoscalerBehavior{}
+		}, false),
+		Entry("empty behavior equals the defaulted behavior", func(spec *autoscalingv2.HorizontalPodAutoscalerSpec) {
+			spec.Behavior = &autoscalingv2.HorizontalPodAutoscalerBehavior{}
+		}, func(spec *autoscalingv2.HorizontalPodAutoscalerSpec) {
+			spec.Behavior = DefaultBehavior(&autoscalingv2.HorizontalPodAutoscalerBehavior{})
+		}, true),
+		Entry("different stabilization window", func(spec *autoscalingv2.HorizontalPodAutoscalerSpec) {
+			spec.Behavior = &autoscalingv2.HorizontalPodAutoscalerBehavior{
+				ScaleDown: &autoscalingv2.HPAScalingRules{StabilizationWindowSeconds: int32Ptr(300)},
+			}
+		}, func(spec *autoscalingv2.HorizontalPodAutoscalerSpec) {
+			spec.Behavior = &autoscalingv2.HorizontalPodAutoscalerBehavior{
+				ScaleDown: &autoscalingv2.HPAScalingRules{StabilizationWindowSeconds: int32Ptr(60)},
+			}
+		}, false),
+		Entry("scaling policies in a different order", func(spec *autoscalingv2.HorizontalPodAutoscalerSpec) {
+			spec.Behavior = &autoscalingv2.HorizontalPodAutoscalerBehavior{
+				ScaleUp: &autoscalingv2.HPAScalingRules{Policies: []autoscalingv2.HPAScalingPolicy{
+					{Type: autoscalingv2.PodsScalingPolicy, Value: 2, PeriodSeconds: 60},
+					{Type: autoscalingv2.PercentScalingPolicy, Value: 50, PeriodSeconds: 60},
+				}},
+			}
+		}, func(spec *autoscalingv2.HorizontalPodAutoscalerSpec) {
+			spec.Behavior = &autoscalingv2.HorizontalPodAutoscalerBehavior{
+				ScaleUp: &autoscalingv2.HPAScalingRules{Policies: []autoscalingv2.HPAScalingPolicy{
+					{Type: autoscalingv2.PercentScalingPolicy, Value: 50, PeriodSeconds: 60},
+					{Type: autoscalingv2.PodsScalingPolicy, Value: 2, PeriodSeconds: 60},
+				}},
+			}
+		}, true),
+	)
+})
+
+var _ = Describe("MergeMetrics", func() {
+	cpuValue := CPUValueMetric(resource.MustParse("500m"))
+	DescribeTable("merging annotation metrics over a profile",
+		func(base, overrides, expected []autoscalingv2.MetricSpec) {
+			Expect(MergeMetrics(base, overrides)).Should(Equal(expected))
+		},
+		Entry("without a profile",
+			nil,
+			[]autoscalingv2.MetricSpec{resourceMetric(corev1.ResourceCPU, 70), resourceMetric(corev1.ResourceMemory, 75)},
+			[]autoscalingv2.MetricSpec{resourceMetric(corev1.ResourceCPU, 70), resourceMetric(corev1.ResourceMemory, 75)}),
+		Entry("keeping overrides with the same name without a profile",
+			nil,
+			[]autoscalingv2.MetricSpec{resourceMetric(corev1.ResourceCPU, 70), cpuValue},
+			[]autoscalingv2.MetricSpec{resourceMetric(corev1.ResourceCPU, 70), cpuValue}),
+		Entry("keeping external metrics which differ only by selector without a profile",
+			nil,
+			[]autoscalingv2.MetricSpec{externalMetric("reqs", "100", map[string]string{"svc": "a"}), externalMetric("reqs", "200", map[string]string{"svc": "b"})},
+			[]autoscalingv2.MetricSpec{externalMetric("reqs", "100", map[string]string{"svc": "a"}), externalMetric("reqs", "200", map[string]string{"svc": "b"})}),
+		Entry("replacing the profile metric in place",
+			[]autoscalingv2.MetricSpec{resourceMetric(corev1.ResourceCPU, 80), resourceMetric(corev1.ResourceMemory, 75)},
+			[]autoscalingv2.MetricSpec{resourceMetric(corev1.ResourceCPU, 60), podsMetric("queue_depth", "30")},
+			[]autoscalingv2.MetricSpec{resourceMetric(corev1.ResourceCPU, 60), resourceMetric(corev1.ResourceMemory, 75), podsMetric("queue_depth", "30")}),
+		Entry("replacing the profile metric with every override of the same name",
+			[]autoscalingv2.MetricSpec{resourceMetric(corev1.ResourceCPU, 80), resourceMetric(corev1.ResourceMemory, 75)},
+			[]autoscalingv2.MetricSpec{resourceMetric(corev1.ResourceCPU, 70), cpuValue},
+			[]autoscalingv2.MetricSpec{resourceMetric(corev1.ResourceCPU, 70), cpuValue, resourceMetric(corev1.ResourceMemory, 75)}),
+		Entry("replacing every profile metric of the same name",
+			[]autoscalingv2.MetricSpec{externalMetric("reqs", "100", map[string]string{"svc": "a"}), externalMetric("reqs", "200", map[string]string{"svc": "b"})},
+			[]autoscalingv2.MetricSpec{externalMetric("reqs", "300", map[string]string{"svc": "c"})},
+			[]autoscalingv2.MetricSpec{externalMetric("reqs", "300", map[string]string{"svc": "c"})}),
+		Entry("keeping metrics with the same name but a different source type",
+			[]autoscalingv2.MetricSpec{podsMetric("reqs", "30")},
+			[]autoscalingv2.MetricSpec{externalMetric("reqs", "100", map[string]string{"svc": "a"})},
+			[]autoscalingv2.MetricSpec{podsMetric("reqs", "30"), externalMetric("reqs", "100", map[string]string{"svc": "a"})}),
+	)
+
+	It("Should keep every annotation metric with the same name", func() {
+		spec, err := HPASpecFromAnnotations(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			consts.HPAMaxReplicas:                                 "10",
+			consts.HPACpuTargetAverageUtilization:                 "70",
+			consts.HPACpuTargetAverageValue:                       "500m",
+			consts.PrometheusMetricName:                           "reqs",
+			consts.PrometheusMetricSelector:                       "svc=a",
+			consts.PrometheusTargetValue:                          "100",
+			consts.IndexedKey(consts.PrometheusMetricName, 1):     "reqs",
+			consts.IndexedKey(consts.PrometheusMetricSelector, 1): "svc=b",
+			consts.IndexedKey(consts.PrometheusTargetValue, 1):    "200",
+		}}}, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(spec.Metrics).Should(HaveLen(4))
+		var selectors []string
+		for _, m := range spec.Metrics {
+			if m.External != nil {
+				selectors = append(selectors, m.External.Metric.Selector.String())
+			}
+		}
+		Expect(selectors).Should(HaveLen(2))
+		Expect(selectors[0]).ShouldNot(Equal(selectors[1]))
+	})
+})
+
+var _ = Describe("EqualMetrics", func() {
+	DescribeTable("comparing metrics",
+		func(a, b []autoscalingv2.MetricSpec, equal bool) {
+			Expect(EqualMetrics(a, b)).Should(Equal(equal))
+			Expect(EqualMetrics(b, a)).Should(Equal(equal))
+		},
+		Entry("both empty", nil, []autoscalingv2.MetricSpec{}, true),
+		Entry("different length",
+			[]autoscalingv2.MetricSpec{resourceMetric(corev1.ResourceCPU, 80)},
+			[]autoscalingv2.MetricSpec{resourceMetric(corev1.ResourceCPU, 80), resourceMetric(corev1.ResourceMemory, 70)},
+			false),
+		Entry("different resource utilization",
+			[]autoscalingv2.MetricSpec{resourceMetric(corev1.ResourceCPU, 80)},
+			[]autoscalingv2.MetricSpec{resourceMetric(corev1.ResourceCPU, 60)},
+			false),
+		Entry("different resource name",
+			[]autoscalingv2.MetricSpec{resourceMetric(corev1.ResourceCPU, 80)},
+			[]autoscalingv2.MetricSpec{resourceMetric(corev1.ResourceMemory, 80)},
+			false),
+		Entry("different order",
+			[]autoscalingv2.MetricSpec{resourceMetric(corev1.ResourceCPU, 80), podsMetric("qps", "100"), externalMetric("lag", "30", nil)},
+			[]autoscalingv2.MetricSpec{externalMetric("lag", "30", nil), resourceMetric(corev1.ResourceCPU, 80), podsMetric("qps", "100")},
+			true),
+		Entry("duplicated metric does not match another metric",
+			[]autoscalingv2.MetricSpec{resourceMetric(corev1.ResourceCPU, 80), resourceMetric(corev1.ResourceCPU, 80)},
+			[]autoscalingv2.MetricSpec{resourceMetric(corev1.ResourceCPU, 80), resourceMetric(corev1.ResourceMemory, 80)},
+			false),
+		Entry("different container",
+			[]autoscalingv2.MetricSpec{containerMetric("app", 80)},
+			[]autoscalingv2.MetricSpec{containerMetric("sidecar", 80)},
+			false),
+		Entry("different container utilization",
+			[]autoscalingv2.MetricSpec{containerMetric("app", 80)},
+			[]autoscalingv2.MetricSpec{containerMetric("app", 60)},
+			false),
+		Entry("external quantities with the same value",
+			[]autoscalingv2.MetricSpec{externalMetric("lag", "500m", nil)},
+			[]autoscalingv2.MetricSpec{externalMetric("lag", "0.5", nil)},
+			true),
+		Entry("different external target",
+			[]autoscalingv2.MetricSpec{externalMetric("lag", "30", nil)},
+			[]autoscalingv2.MetricSpec{externalMetric("lag", "40", nil)},
+			false),
+		Entry("different external selector",
+			[]autoscalingv2.MetricSpec{externalMetric("lag", "30", map[string]string{"topic": "orders"})},
+			[]autoscalingv2.MetricSpec{externalMetric("lag", "30", map[string]string{"topic": "payments"})},
+			false),
+		Entry("different pods target",
+			[]autoscalingv2.MetricSpec{podsMetric("qps", "100")},
+			[]autoscalingv2.MetricSpec{podsMetric("qps", "200")},
+			false),
+		Entry("different described object",
+			[]autoscalingv2.MetricSpec{objectMetric("requests", "web", "1k")},
+			[]autoscalingv2.MetricSpec{objectMetric("requests", "api", "1k")},
+			false),
+		Entry("object quantities with the same value",
+			[]autoscalingv2.MetricSpec{objectMetric("requests", "web", "1k")},
+			[]autoscalingv2.MetricSpec{objectMetric("requests", "web", "1000")},
+			true),
+		Entry("same name but different source type",
+			[]autoscalingv2.MetricSpec{podsMetric("qps", "100")},
+			[]autoscalingv2.MetricSpec{externalMetric("qps", "100", nil)},
+			false),
+	)
+})
+
+var _ = Describe("DiffHPA", func() {
+	It("Should list the changed fields", func() {
+		current := newHPA(func(spec *autoscalingv2.HorizontalPodAutoscalerSpec) {
+			spec.Metrics = append(spec.Metrics, podsMetric("qps", "100"))
+		})
+		desired := newHPA(func(spec *autoscalingv2.HorizontalPodAutoscalerSpec) {
+			spec.MaxReplicas = 20
+			spec.Metrics = []autoscalingv2.MetricSpec{resourceMetric(corev1.ResourceCPU, 60), resourceMetric(corev1.ResourceMemory, 70)}
+			spec.Behavior = &autoscalingv2.HorizontalPodAutoscalerBehavior{}
+		})
+		Expect(DiffHPA(current, desired)).Should(Equal([]string{
+			"spec.maxReplicas: 10 -> 20",
+			"spec.metrics[Resource/cpu]: changed",
+			"spec.metrics[Resource/memory]: added",
+			"spec.metrics[Pods/qps]: removed",
+			"spec.behavior: added",
+		}))
+	})
+
+	It("Should ignore defaulted fields", func() {
+		current := newHPA(func(spec *autoscalingv2.HorizontalPodAutoscalerSpec) {
+			spec.MinReplicas = int32Ptr(1)
+		})
+		desired := newHPA(func(spec *autoscalingv2.HorizontalPodAutoscalerSpec) {
+			spec.MinReplicas = nil
+			spec.Metrics = nil
+		})
+		Expect(DiffHPA(current, desired)).Should(BeEmpty())
+	})
+})
@@ -0,0 +1,81 @@
+package kube
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NameTemplateData HPA名称模板中可以使用的工作负载字段
+type NameTemplateData struct {
+	// Name 工作负载名称
+	Name string
+	// Namespace 工作负载所在的命名空间
+	Namespace string
+	// Kind 工作负载类型，例如 Deployment
+	Kind string
+}
+
+// nameTemplateFuncs HPA名称模板中可以使用的函数
+var nameTemplateFuncs = template.FuncMap{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// ParseNameTemplate 解析HPA名称模板，例如 {{.Name}}-{{.Kind | lower}}-hpa，字段见 NameTemplateData
+// 解析后会用示例工作负载执行一次，提前发现引用了不存在的字段等错误；text为空时返回nil
+func ParseNameTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New("name").Funcs(nameTemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := executeNameTemplate(tmpl, NameTemplateData{Name: "example", Namespace: "default", Kind: KindDeployment}); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// executeNameTemplate 执行HPA名称模板，结果不是合法的对象名称时返回错误
+func executeNameTemplate(tmpl *template.Template, data NameTemplateData) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	name := b.String()
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return "", fmt.Errorf("rendered name %q is invalid: %s", name, strings.Join(errs, ", "))
+	}
+	return name, nil
+}
+
+// HPAName 返回为工作负载创建的HPA的名称
+// 工作负载设置了 hpa.infraflow.co/nameTemplate 注解时使用注解中的模板，否则使用 defaultTemplate
+// （启动参数 --hpa-name-template），两者都未设置时与VPA相同，见 AutoscalerName
+// 注解中的模板不合法时返回注解校验错误
+func HPAName(workload client.Object, kind string, defaultTemplate *template.Template) (string, error) {
+	data := NameTemplateData{Name: workload.GetName(), Namespace: workload.GetNamespace(), Kind: kind}
+	if text := workload.GetAnnotations()[consts.HPANameTemplate]; text != "" {
+		tmpl, err := ParseNameTemplate(text)
+		if err == nil {
+			var name string
+			if name, err = executeNameTemplate(tmpl, data); err == nil {
+				return name, nil
+			}
+		}
+		return "", &ValidationError{Errors: field.ErrorList{
+			field.Invalid(annotationPath(consts.HPANameTemplate), text, err.Error()),
+		}}
+	}
+	if defaultTemplate == nil {
+		return AutoscalerName(workload.GetName(), kind), nil
+	}
+	return executeNameTemplate(defaultTemplate, data)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"strings"
+
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("HPAName", func() {
+	newStatefulSet := func(annotations map[string]string) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "shop", Annotations: annotations}}
+	}
+
+	DescribeTable("rendering HPA names",
+		func(defaultTemplate string, annotations map[string]string, expected string) {
+			tmpl, err := ParseNameTemplate(defaultTemplate)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(HPAName(newStatefulSet(annotations), KindStatefulSet, tmpl)).Should(Equal(expected))
+		},
+		Entry("without a template", "", nil, "api-statefulset"),
+		Entry("with the default template", "{{.Name}}-{{.Kind | lower}}-hpa", nil, "api-statefulset-hpa"),
+		Entry("with the namespace", "{{.Namespace}}-{{.Name}}", nil, "shop-api"),
+		Entry("with the annotation overriding the default template", "{{.Name}}-{{.Kind | lower}}-hpa",
+			map[string]string{consts.HPANameTemplate: "{{.Name}}-autoscaler"}, "api-autoscaler"),
+		Entry("with an empty annotation", "{{.Name}}-hpa",
+			map[string]string{consts.HPANameTemplate: ""}, "api-hpa"),
+	)
+
+	DescribeTable("rejecting invalid templates",
+		func(template string) {
+			_, err := ParseNameTemplate(template)
+			Expect(err).Should(HaveOccurred())
+
+			_, err = HPAName(newStatefulSet(map[string]string{consts.HPANameTemplate: template}), KindStatefulSet, nil)
+			var invalid *ValidationError
+			Expect(err).Should(BeAssignableToTypeOf(invalid))
+			Expect(err.Error()).Should(ContainSubstring(consts.HPANameTemplate))
+		},
+		Entry("with a syntax error", "{{.Name"),
+		Entry("with an unknown field", "{{.Labels}}"),
+		Entry("with an unknown function", "{{.Name | title}}"),
+		Entry("rendering an invalid name", "{{.Kind}}_{{.Name}}"),
+		Entry("rendering an empty name", "{{/* empty */}}"),
+	)
+})
+
+var _ = Describe("AutoscalerObjectMeta", func() {
+	It("Should set the standard labels and the annotation hash", func() {
+		deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Name:        "web",
+			Namespace:   "shop",
+			Annotations: map[string]string{consts.HPAMaxReplicas: "10", "unrelated": "value"},
+		}}
+		meta := AutoscalerObjectMeta(deployment, KindDeployment, "web-hpa")
+		Expect(meta.Name).Should(Equal("web-hpa"))
+		Expect(meta.Namespace).Should(Equal("shop"))
+		Expect(meta.Labels).Should(Equal(map[string]string{
+			consts.ManagedByLabel:    consts.ManagedByValue,
+			consts.WorkloadKindLabel: KindDeployment,
+			consts.WorkloadNameLabel: "web",
+		}))
+		Expect(meta.Annotations).Should(HaveKeyWithValue(consts.SourceHashAnnotation, AnnotationsHash(deployment.Annotations)))
+
+		deployment.Annotations["unrelated"] = "changed"
+		Expect(AutoscalerObjectMeta(deployment, KindDeployment, "web-hpa").Annotations).Should(Equal(meta.Annotations))
+		deployment.Annotations[consts.HPAMaxReplicas] = "20"
+		Expect(AutoscalerObjectMeta(deployment, KindDeployment, "web-hpa").Annotations).ShouldNot(Equal(meta.Annotations))
+	})
+
+	It("Should omit the name label when the name is too long for a label value", func() {
+		deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 64), Namespace: "shop"}}
+		meta := AutoscalerObjectMeta(deployment, KindDeployment, deployment.Name)
+		Expect(meta.Labels).ShouldNot(HaveKey(consts.WorkloadNameLabel))
+		Expect(meta.Labels).Should(HaveKeyWithValue(consts.WorkloadKindLabel, KindDeployment))
+	})
+})
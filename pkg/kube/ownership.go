@@ -0,0 +1,53 @@
+package kube
+
+import (
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// IsManaged 检查自动扩缩容对象是否由 Controller 为指定工作负载管理：
+// 对象的 controller owner reference 指向该工作负载，或没有 controller owner reference 但带有 managed-by 标签
+// 用户手动创建的同名对象、由其他工作负载控制的同名对象都不满足，Controller 不会修改或删除它们
+func IsManaged(obj, workload client.Object) bool {
+	if owner := metav1.GetControllerOf(obj); owner != nil {
+		return owner.UID == workload.GetUID()
+	}
+	return obj.GetLabels()[consts.ManagedByLabel] == consts.ManagedByValue
+}
+
+// AutoscalerObjectMeta 返回为工作负载生成的HPA/VPA的元数据，包括：
+// - managed-by 标签
+// - 工作负载类型和名称标签，名称超过标签值的长度限制时不设置名称标签
+// - 工作负载自动扩缩容注解的哈希值（见 AnnotationsHash），与 status.infraflow.co/autoscale 中的 observedAnnotationsHash 相同
+func AutoscalerObjectMeta(workload client.Object, kind, name string) metav1.ObjectMeta {
+	labels := map[string]string{
+		consts.ManagedByLabel:    consts.ManagedByValue,
+		consts.WorkloadKindLabel: kind,
+	}
+	if len(validation.IsValidLabelValue(workload.GetName())) == 0 {
+		labels[consts.WorkloadNameLabel] = workload.GetName()
+	}
+	return metav1.ObjectMeta{
+		Name:        name,
+		Namespace:   workload.GetNamespace(),
+		Labels:      labels,
+		Annotations: map[string]string{consts.SourceHashAnnotation: AnnotationsHash(workload.GetAnnotations())},
+	}
+}
+
+// HasMetadata 检查current是否已包含desired中的所有标签和注解
+func HasMetadata(current, desired client.Object) bool {
+	return containsAll(current.GetLabels(), desired.GetLabels()) && containsAll(current.GetAnnotations(), desired.GetAnnotations())
+}
+
+// containsAll 检查m是否包含sub中的所有键值对
+func containsAll(m, sub map[string]string) bool {
+	for key, val := range sub {
+		if v, ok := m[key]; !ok || v != val {
+			return false
+		}
+	}
+	return true
+}
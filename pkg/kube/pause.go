@@ -0,0 +1,75 @@
+package kube
+
+import (
+	"strconv"
+
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// PausedByAnnotation 工作负载设置了 hpa.infraflow.co/paused 注解
+	PausedByAnnotation = "annotation"
+	// PausedByNamespace 工作负载所在的命名空间设置了 autoscale.infraflow.co/paused 标签
+	PausedByNamespace = "namespace"
+	// PausedGlobally Controller 以 --global-pause 启动
+	PausedGlobally = "global"
+)
+
+// HPAPaused 检查工作负载是否通过 hpa.infraflow.co/paused 注解暂停了HPA，注解的值不是布尔值时返回注解校验错误
+func HPAPaused(workload client.Object) (bool, error) {
+	value, ok := workload.GetAnnotations()[consts.HPAPaused]
+	if !ok {
+		return false, nil
+	}
+	paused, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, &ValidationError{Errors: field.ErrorList{
+			field.Invalid(annotationPath(consts.HPAPaused), value, "must be a boolean"),
+		}}
+	}
+	return paused, nil
+}
+
+// IsPaused 检查HPA是否已被暂停，即带有 autoscale.infraflow.co/paused-replicas 注解
+func IsPaused(hpa *autoscalingv2.HorizontalPodAutoscaler) bool {
+	_, ok := hpa.Annotations[consts.PausedReplicasAnnotation]
+	return ok
+}
+
+// PausedReplicas 返回暂停时HPA固定的副本数
+// HPA已被暂停时沿用之前固定的副本数，否则使用工作负载当前的副本数（spec.replicas，未设置时为1）
+// HPA的minReplicas不能小于1，副本数为0的工作负载固定为1，HPA不会对副本数为0的工作负载扩缩容
+func PausedReplicas(current *autoscalingv2.HorizontalPodAutoscaler, workload client.Object) int32 {
+	if current != nil {
+		if replicas, err := strconv.ParseInt(current.Annotations[consts.PausedReplicasAnnotation], 10, 32); err == nil && replicas > 0 {
+			return int32(replicas)
+		}
+	}
+	replicas := int32(1)
+	switch w := workload.(type) {
+	case *appsv1.Deployment:
+		if w.Spec.Replicas != nil {
+			replicas = *w.Spec.Replicas
+		}
+	case *appsv1.StatefulSet:
+		if w.Spec.Replicas != nil {
+			replicas = *w.Spec.Replicas
+		}
+	}
+	return max(replicas, 1)
+}
+
+// PauseHPA 将HPA的最小、最大副本数固定为replicas，并通过 autoscale.infraflow.co/paused-replicas 注解记录，
+// 其余配置保持不变，恢复时重新按注解或策略构建HPA即可
+func PauseHPA(hpa *autoscalingv2.HorizontalPodAutoscaler, replicas int32) {
+	hpa.Spec.MinReplicas = &replicas
+	hpa.Spec.MaxReplicas = replicas
+	if hpa.Annotations == nil {
+		hpa.Annotations = map[string]string{}
+	}
+	hpa.Annotations[consts.PausedReplicasAnnotation] = strconv.Itoa(int(replicas))
+}
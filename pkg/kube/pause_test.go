@@ -0,0 +1,72 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Pause", func() {
+	DescribeTable("HPAPaused",
+		func(annotations map[string]string, expected bool, invalid bool) {
+			paused, err := HPAPaused(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Annotations: annotations}})
+			if invalid {
+				var validationErr *ValidationError
+				Expect(err).Should(BeAssignableToTypeOf(validationErr))
+				Expect(err.Error()).Should(ContainSubstring(consts.HPAPaused))
+				return
+			}
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(paused).Should(Equal(expected))
+		},
+		Entry("without the annotation", nil, false, false),
+		Entry("with true", map[string]string{consts.HPAPaused: "true"}, true, false),
+		Entry("with false", map[string]string{consts.HPAPaused: "false"}, false, false),
+		Entry("with an invalid value", map[string]string{consts.HPAPaused: "yes"}, false, true),
+	)
+
+	DescribeTable("PausedReplicas",
+		func(current *autoscalingv2.HorizontalPodAutoscaler, replicas *int32, expected int32) {
+			deployment := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: replicas}}
+			Expect(PausedReplicas(current, deployment)).Should(Equal(expected))
+		},
+		Entry("with the replicas of the workload", nil, int32Ptr(4), int32(4)),
+		Entry("without replicas on the workload", nil, nil, int32(1)),
+		Entry("with a workload scaled to zero", nil, int32Ptr(0), int32(1)),
+		Entry("with an HPA which is not paused", &autoscalingv2.HorizontalPodAutoscaler{}, int32Ptr(4), int32(4)),
+		Entry("with an HPA which is already paused", &autoscalingv2.HorizontalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{consts.PausedReplicasAnnotation: "6"},
+		}}, int32Ptr(4), int32(6)),
+	)
+
+	It("Should pin the replicas and mark the HPA as paused", func() {
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			MinReplicas: int32Ptr(2), MaxReplicas: 10,
+		}}
+		Expect(IsPaused(hpa)).Should(BeFalse())
+		PauseHPA(hpa, 3)
+		Expect(hpa.Spec.MinReplicas).Should(HaveValue(Equal(int32(3))))
+		Expect(hpa.Spec.MaxReplicas).Should(Equal(int32(3)))
+		Expect(hpa.Annotations).Should(HaveKeyWithValue(consts.PausedReplicasAnnotation, "3"))
+		Expect(IsPaused(hpa)).Should(BeTrue())
+	})
+})
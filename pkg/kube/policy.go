@@ -0,0 +1,57 @@
+package kube
+
+import (
+	"sort"
+
+	autoscalev1alpha1 "github.com/infraflows/autoscale-controller/pkg/apis/autoscale/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PolicySelects 判断 AutoscalePolicy 是否选中了工作负载
+// targetRef 按类型和名称匹配，selector 按工作负载的标签匹配，两者都只选择与策略相同命名空间中的工作负载
+func PolicySelects(policy *autoscalev1alpha1.AutoscalePolicy, workload client.Object, kind string) bool {
+	if policy.Namespace != workload.GetNamespace() {
+		return false
+	}
+	if ref := policy.Spec.TargetRef; ref != nil {
+		return ref.Kind == kind && ref.Name == workload.GetName()
+	}
+	if policy.Spec.Selector == nil {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(policy.Spec.Selector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(workload.GetLabels()))
+}
+
+// SelectPolicy 从策略列表中选出作用于工作负载的 AutoscalePolicy，没有匹配的策略时返回nil
+// 多个策略同时匹配时按以下顺序选择：
+// 1. 通过 targetRef 指定工作负载的策略优先于通过 selector 选择的策略
+// 2. 创建时间较早的策略优先
+// 3. 名称按字母顺序较小的策略优先
+func SelectPolicy(policies []autoscalev1alpha1.AutoscalePolicy, workload client.Object, kind string) *autoscalev1alpha1.AutoscalePolicy {
+	var matched []*autoscalev1alpha1.AutoscalePolicy
+	for i := range policies {
+		if PolicySelects(&policies[i], workload, kind) {
+			matched = append(matched, &policies[i])
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if (a.Spec.TargetRef != nil) != (b.Spec.TargetRef != nil) {
+			return a.Spec.TargetRef != nil
+		}
+		if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+			return a.CreationTimestamp.Before(&b.CreationTimestamp)
+		}
+		return a.Name < b.Name
+	})
+	return matched[0]
+}
@@ -0,0 +1,24 @@
+package kube
+
+import (
+	"math"
+
+	autoscalev1alpha1 "github.com/infraflows/autoscale-controller/pkg/apis/autoscale/v1alpha1"
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ProfileName 返回工作负载通过 hpa.infraflow.co/profile 引用的 ClusterAutoscaleProfile 名称，没有引用时返回空字符串
+func ProfileName(workload client.Object) string {
+	return workload.GetAnnotations()[consts.HPAProfile]
+}
+
+// ProfileNotFoundError 返回引用的 ClusterAutoscaleProfile 不存在时的校验错误
+// 创建该配置后工作负载会被重新协调，因此作为注解校验错误报告，不重新入队
+func ProfileNotFoundError(name string) error {
+	return &ValidationError{Errors: field.ErrorList{field.NotFound(annotationPath(consts.HPAProfile), name)}}
+}
+
+// unknownProfile 校验注解时代替引用的 ClusterAutoscaleProfile，不要求 maxReplicas，也不限制副本数
+var unknownProfile = &autoscalev1alpha1.HPASpec{MaxReplicas: math.MaxInt32}
@@ -0,0 +1,191 @@
+package kube
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	autoscalev1alpha1 "github.com/infraflows/autoscale-controller/pkg/apis/autoscale/v1alpha1"
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// scheduleSearchLimit 查找窗口最近一次开始时间时最多遍历的触发次数，
+// 例如每分钟触发、持续一周的窗口需要遍历约一万次
+const scheduleSearchLimit = 10080
+
+// buildSchedules 根据工作负载的注解构建定时窗口
+// 支持的注解（可通过 <index>. 前缀配置多个窗口，见 consts.IndexedKey）：
+// - schedule.hpa.infraflow.co/cron: 窗口开始时间的cron表达式
+// - schedule.hpa.infraflow.co/timeZone: cron表达式使用的时区，默认为UTC
+// - schedule.hpa.infraflow.co/duration: 窗口持续时间
+// - schedule.hpa.infraflow.co/minReplicas、maxReplicas: 窗口内的副本数范围，至少配置一个
+func buildSchedules(p *annotationParser) []autoscalev1alpha1.ScheduleWindow {
+	var windows []autoscalev1alpha1.ScheduleWindow
+	prefix := prefixOf(consts.ScheduleCron)
+	for _, group := range groupIndexed(p.annotations, prefix) {
+		cronKey := group.key(prefix, fieldOf(consts.ScheduleCron))
+		timeZoneKey := group.key(prefix, fieldOf(consts.ScheduleTimeZone))
+		durationKey := group.key(prefix, fieldOf(consts.ScheduleDuration))
+		minKey := group.key(prefix, fieldOf(consts.ScheduleMinReplicas))
+		maxKey := group.key(prefix, fieldOf(consts.ScheduleMaxReplicas))
+
+		errs := len(p.errs)
+		window := autoscalev1alpha1.ScheduleWindow{
+			Schedule:    p.annotations[cronKey],
+			TimeZone:    p.annotations[timeZoneKey],
+			MinReplicas: p.int32(minKey, 1, math.MaxInt32),
+			MaxReplicas: p.int32(maxKey, 1, math.MaxInt32),
+		}
+		if window.Schedule == "" {
+			p.required(cronKey, "cron expression is required when other fields of the schedule are set")
+		} else if _, err := ParseCron(window.Schedule); err != nil {
+			p.invalid(cronKey, window.Schedule, "must be a cron expression: "+err.Error())
+		}
+		if _, err := time.LoadLocation(window.TimeZone); err != nil {
+			p.invalid(timeZoneKey, window.TimeZone, "must be an IANA time zone, e.g. Asia/Shanghai")
+		}
+		if val, ok := p.annotations[durationKey]; !ok {
+			p.required(durationKey, "duration is required")
+		} else if d, err := time.ParseDuration(val); err != nil {
+			p.invalid(durationKey, val, "must be a duration, e.g. 10h")
+		} else if reason := validateScheduleDuration(d); reason != "" {
+			p.invalid(durationKey, val, reason)
+		} else {
+			window.Duration = metav1.Duration{Duration: d}
+		}
+		_, hasMin := p.annotations[minKey]
+		_, hasMax := p.annotations[maxKey]
+		switch {
+		case !hasMin && !hasMax:
+			p.required(minKey, "at least one of minReplicas or maxReplicas is required")
+		case window.MinReplicas != nil && window.MaxReplicas != nil && *window.MinReplicas > *window.MaxReplicas:
+			p.invalid(maxKey, p.annotations[maxKey], "must be greater than or equal to "+minKey)
+		}
+		if len(p.errs) == errs {
+			windows = append(windows, window)
+		}
+	}
+	return windows
+}
+
+// validateScheduleDuration 校验窗口持续时间，必须是正的整数分钟，与cron表达式的精度一致；合法时返回空字符串
+func validateScheduleDuration(d time.Duration) string {
+	if d <= 0 || d%time.Minute != 0 {
+		return "must be a positive whole number of minutes"
+	}
+	return ""
+}
+
+// ValidateSchedules 校验AutoscalePolicy、ClusterAutoscaleProfile中定时窗口的cron表达式、时区和持续时间，
+// 与注解中配置的窗口使用相同的规则（见 buildSchedules），副本数范围由CRD的校验规则检查
+func ValidateSchedules(windows []autoscalev1alpha1.ScheduleWindow, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i := range windows {
+		window := &windows[i]
+		if _, err := ParseCron(window.Schedule); err != nil {
+			errs = append(errs, field.Invalid(path.Index(i).Child("schedule"), window.Schedule,
+				"must be a cron expression: "+err.Error()))
+		}
+		if _, err := time.LoadLocation(window.TimeZone); err != nil {
+			errs = append(errs, field.Invalid(path.Index(i).Child("timeZone"), window.TimeZone,
+				"must be an IANA time zone, e.g. Asia/Shanghai"))
+		}
+		if reason := validateScheduleDuration(window.Duration.Duration); reason != "" {
+			errs = append(errs, field.Invalid(path.Index(i).Child("duration"), window.Duration.Duration.String(), reason))
+		}
+	}
+	return errs
+}
+
+// ScheduleName 返回窗口在工作负载状态中显示的名称，未设置名称时使用cron表达式
+func ScheduleName(window *autoscalev1alpha1.ScheduleWindow) string {
+	if window.Name != "" {
+		return window.Name
+	}
+	return window.Schedule
+}
+
+// ActiveSchedule 返回now时生效的定时窗口，以及下一次有窗口开始或结束的时间
+// 多个窗口同时生效时使用列表中的第一个；没有生效的窗口时返回nil，窗口不会再开始时返回的时间为零值
+// 窗口的cron表达式、时区或持续时间不合法时返回错误
+func ActiveSchedule(windows []autoscalev1alpha1.ScheduleWindow, now time.Time) (*autoscalev1alpha1.ScheduleWindow, time.Time, error) {
+	var active *autoscalev1alpha1.ScheduleWindow
+	var next time.Time
+	for i := range windows {
+		window := &windows[i]
+		isActive, boundary, err := scheduleState(window, now)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("schedule %q: %w", ScheduleName(window), err)
+		}
+		if isActive && active == nil {
+			active = window
+		}
+		if !boundary.IsZero() && (next.IsZero() || boundary.Before(next)) {
+			next = boundary
+		}
+	}
+	return active, next, nil
+}
+
+// scheduleState 返回窗口在now时是否生效，以及窗口下一次开始（未生效时）或结束（生效时）的时间
+// 窗口在每次cron触发时开始，持续 Duration；生效期间再次触发时从最近一次触发时间重新计算结束时间
+func scheduleState(window *autoscalev1alpha1.ScheduleWindow, now time.Time) (bool, time.Time, error) {
+	schedule, err := ParseCron(window.Schedule)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	loc, err := time.LoadLocation(window.TimeZone)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	duration := window.Duration.Duration
+	if reason := validateScheduleDuration(duration); reason != "" {
+		return false, time.Time{}, fmt.Errorf("duration %s %s", duration, reason)
+	}
+
+	now = now.In(loc)
+	// (now-duration, now] 内的第一次触发说明窗口正在生效
+	start := schedule.Next(now.Add(-duration))
+	if start.IsZero() || start.After(now) {
+		return false, schedule.Next(now), nil
+	}
+	for i := 0; i < scheduleSearchLimit; i++ {
+		next := schedule.Next(start)
+		if next.IsZero() || next.After(now) {
+			break
+		}
+		start = next
+	}
+	return true, start.Add(duration), nil
+}
+
+// ApplySchedule 返回用窗口中的副本数覆盖后的HPA配置，window为nil时返回原配置，见 overrideReplicas
+func ApplySchedule(spec *autoscalev1alpha1.HPASpec, window *autoscalev1alpha1.ScheduleWindow) *autoscalev1alpha1.HPASpec {
+	if window == nil {
+		return spec
+	}
+	return overrideReplicas(spec, window.MinReplicas, window.MaxReplicas)
+}
+
+// overrideReplicas 返回用min、max覆盖副本数后的HPA配置副本，为nil的值保持不变
+// 只覆盖其中一个值时，另一个值会被调整以保证 minReplicas <= maxReplicas
+func overrideReplicas(spec *autoscalev1alpha1.HPASpec, min, max *int32) *autoscalev1alpha1.HPASpec {
+	result := spec.DeepCopy()
+	if min != nil {
+		v := *min
+		result.MinReplicas = &v
+		if result.MaxReplicas < v {
+			result.MaxReplicas = v
+		}
+	}
+	if max != nil {
+		result.MaxReplicas = *max
+		if result.MinReplicas != nil && *result.MinReplicas > result.MaxReplicas {
+			v := result.MaxReplicas
+			result.MinReplicas = &v
+		}
+	}
+	return result
+}
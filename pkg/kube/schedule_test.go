@@ -0,0 +1,167 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"time"
+
+	autoscalev1alpha1 "github.com/infraflows/autoscale-controller/pkg/apis/autoscale/v1alpha1"
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var _ = Describe("Schedule", func() {
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+	at := func(layout string) time.Time {
+		t, err := time.ParseInLocation("2006-01-02 15:04", layout, shanghai)
+		Expect(err).ShouldNot(HaveOccurred())
+		return t
+	}
+	businessHours := autoscalev1alpha1.ScheduleWindow{
+		Name:        "business-hours",
+		Schedule:    "0 8 * * 1-5",
+		TimeZone:    "Asia/Shanghai",
+		Duration:    metav1.Duration{Duration: 10 * time.Hour},
+		MinReplicas: int32Ptr(20),
+	}
+	lunch := autoscalev1alpha1.ScheduleWindow{
+		Schedule:    "30 11 * * *",
+		TimeZone:    "Asia/Shanghai",
+		Duration:    metav1.Duration{Duration: 2 * time.Hour},
+		MinReplicas: int32Ptr(30),
+	}
+	everyMinute := autoscalev1alpha1.ScheduleWindow{
+		Schedule:    "* * * * *",
+		Duration:    metav1.Duration{Duration: 5 * time.Minute},
+		MaxReplicas: int32Ptr(5),
+	}
+
+	DescribeTable("finding the active window",
+		func(windows []autoscalev1alpha1.ScheduleWindow, now, active, next string) {
+			window, boundary, err := ActiveSchedule(windows, at(now))
+			Expect(err).ShouldNot(HaveOccurred())
+			if active == "" {
+				Expect(window).Should(BeNil())
+			} else {
+				Expect(window).ShouldNot(BeNil())
+				Expect(ScheduleName(window)).Should(Equal(active))
+			}
+			if next == "" {
+				Expect(boundary.IsZero()).Should(BeTrue())
+				return
+			}
+			Expect(boundary).Should(BeTemporally("==", at(next)))
+		},
+		Entry("before the window", []autoscalev1alpha1.ScheduleWindow{businessHours}, "2025-06-02 07:30", "", "2025-06-02 08:00"),
+		Entry("at the start of the window", []autoscalev1alpha1.ScheduleWindow{businessHours}, "2025-06-02 08:00", "business-hours", "2025-06-02 18:00"),
+		Entry("during the window", []autoscalev1alpha1.ScheduleWindow{businessHours}, "2025-06-02 17:59", "business-hours", "2025-06-02 18:00"),
+		Entry("at the end of the window", []autoscalev1alpha1.ScheduleWindow{businessHours}, "2025-06-02 18:00", "", "2025-06-03 08:00"),
+		Entry("over the weekend", []autoscalev1alpha1.ScheduleWindow{businessHours}, "2025-06-06 18:30", "", "2025-06-09 08:00"),
+		Entry("with the first of overlapping windows", []autoscalev1alpha1.ScheduleWindow{businessHours, lunch},
+			"2025-06-02 12:00", "business-hours", "2025-06-02 13:30"),
+		Entry("with the second window only", []autoscalev1alpha1.ScheduleWindow{businessHours, lunch},
+			"2025-06-07 12:00", "30 11 * * *", "2025-06-07 13:30"),
+		Entry("extended by later starts", []autoscalev1alpha1.ScheduleWindow{everyMinute}, "2025-06-02 12:00", "* * * * *", "2025-06-02 12:05"),
+		Entry("without windows", nil, "2025-06-02 12:00", "", ""),
+	)
+
+	It("Should return the zero time when no window starts again", func() {
+		window, boundary, err := ActiveSchedule([]autoscalev1alpha1.ScheduleWindow{{
+			Schedule: "0 0 30 2 *", Duration: metav1.Duration{Duration: time.Hour}, MinReplicas: int32Ptr(2),
+		}}, at("2025-06-02 12:00"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(window).Should(BeNil())
+		Expect(boundary.IsZero()).Should(BeTrue())
+	})
+
+	DescribeTable("rejecting invalid windows",
+		func(window autoscalev1alpha1.ScheduleWindow, invalidField string) {
+			_, _, err := ActiveSchedule([]autoscalev1alpha1.ScheduleWindow{window}, at("2025-06-02 12:00"))
+			Expect(err).Should(HaveOccurred())
+
+			errs := ValidateSchedules([]autoscalev1alpha1.ScheduleWindow{businessHours, window}, field.NewPath("spec", "hpa", "schedules"))
+			Expect(errs).Should(HaveLen(1))
+			Expect(errs[0].Field).Should(Equal("spec.hpa.schedules[1]." + invalidField))
+		},
+		Entry("with an invalid schedule", autoscalev1alpha1.ScheduleWindow{Schedule: "0 8 * *", Duration: metav1.Duration{Duration: time.Hour}}, "schedule"),
+		Entry("with an unknown time zone", autoscalev1alpha1.ScheduleWindow{Schedule: "0 8 * * *", TimeZone: "Mars/Olympus", Duration: metav1.Duration{Duration: time.Hour}}, "timeZone"),
+		Entry("without a duration", autoscalev1alpha1.ScheduleWindow{Schedule: "0 8 * * *"}, "duration"),
+		Entry("with a duration in seconds", autoscalev1alpha1.ScheduleWindow{Schedule: "0 8 * * *", Duration: metav1.Duration{Duration: 90 * time.Second}}, "duration"),
+	)
+
+	DescribeTable("overriding the replicas",
+		func(window *autoscalev1alpha1.ScheduleWindow, min, max int32) {
+			spec := &autoscalev1alpha1.HPASpec{MinReplicas: int32Ptr(3), MaxReplicas: 10}
+			result := ApplySchedule(spec, window)
+			Expect(result.MinReplicas).Should(HaveValue(Equal(min)))
+			Expect(result.MaxReplicas).Should(Equal(max))
+			Expect(spec.MinReplicas).Should(HaveValue(Equal(int32(3))))
+		},
+		Entry("without a window", nil, int32(3), int32(10)),
+		Entry("with both replicas", &autoscalev1alpha1.ScheduleWindow{MinReplicas: int32Ptr(5), MaxReplicas: int32Ptr(8)}, int32(5), int32(8)),
+		Entry("raising maxReplicas to minReplicas", &autoscalev1alpha1.ScheduleWindow{MinReplicas: int32Ptr(20)}, int32(20), int32(20)),
+		Entry("lowering minReplicas to maxReplicas", &autoscalev1alpha1.ScheduleWindow{MaxReplicas: int32Ptr(2)}, int32(2), int32(2)),
+	)
+
+	DescribeTable("parsing schedule annotations",
+		func(annotations map[string]string, expected []autoscalev1alpha1.ScheduleWindow, invalidKeys ...string) {
+			annotations[consts.HPAMaxReplicas] = "10"
+			spec, err := HPASpecFromAnnotations(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Annotations: annotations}}, nil)
+			if len(invalidKeys) > 0 {
+				var invalid *ValidationError
+				Expect(err).Should(BeAssignableToTypeOf(invalid))
+				for _, key := range invalidKeys {
+					Expect(err.Error()).Should(ContainSubstring(key))
+				}
+				return
+			}
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(spec.Schedules).Should(Equal(expected))
+		},
+		Entry("with indexed windows", map[string]string{
+			consts.ScheduleCron:                              "0 8 * * 1-5",
+			consts.ScheduleTimeZone:                          "Asia/Shanghai",
+			consts.ScheduleDuration:                          "10h",
+			consts.ScheduleMinReplicas:                       "20",
+			consts.IndexedKey(consts.ScheduleCron, 1):        "0 22 * * *",
+			consts.IndexedKey(consts.ScheduleDuration, 1):    "8h",
+			consts.IndexedKey(consts.ScheduleMaxReplicas, 1): "3",
+		}, []autoscalev1alpha1.ScheduleWindow{
+			{Schedule: "0 8 * * 1-5", TimeZone: "Asia/Shanghai", Duration: metav1.Duration{Duration: 10 * time.Hour}, MinReplicas: int32Ptr(20)},
+			{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: 8 * time.Hour}, MaxReplicas: int32Ptr(3)},
+		}),
+		Entry("without a cron expression", map[string]string{
+			consts.ScheduleDuration: "10h", consts.ScheduleMinReplicas: "20",
+		}, nil, consts.ScheduleCron),
+		Entry("with an invalid cron expression and time zone", map[string]string{
+			consts.ScheduleCron: "0 25 * * *", consts.ScheduleTimeZone: "Mars/Olympus", consts.ScheduleDuration: "10h", consts.ScheduleMinReplicas: "20",
+		}, nil, consts.ScheduleCron, consts.ScheduleTimeZone),
+		Entry("with an invalid duration", map[string]string{
+			consts.ScheduleCron: "0 8 * * *", consts.ScheduleDuration: "30s", consts.ScheduleMinReplicas: "20",
+		}, nil, consts.ScheduleDuration),
+		Entry("without replicas", map[string]string{
+			consts.ScheduleCron: "0 8 * * *", consts.ScheduleDuration: "1h",
+		}, nil, consts.ScheduleMinReplicas),
+		Entry("with minReplicas greater than maxReplicas", map[string]string{
+			consts.ScheduleCron: "0 8 * * *", consts.ScheduleDuration: "1h", consts.ScheduleMinReplicas: "20", consts.ScheduleMaxReplicas: "5",
+		}, nil, consts.ScheduleMaxReplicas),
+	)
+})
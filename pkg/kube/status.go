@@ -0,0 +1,83 @@
+package kube
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AutoscaleStatus 工作负载的自动扩缩容状态，以JSON格式写入 status.infraflow.co/autoscale 注解
+type AutoscaleStatus struct {
+	// HPA 被管理的HPA名称，没有管理HPA时为空
+	HPA string `json:"hpa,omitempty"`
+	// VPA 被管理的VPA名称，没有管理VPA时为空
+	VPA string `json:"vpa,omitempty"`
+	// Policy 提供HPA/VPA配置的 AutoscalePolicy 名称
+	Policy string `json:"policy,omitempty"`
+	// Profile 通过 hpa.infraflow.co/profile 引用的 ClusterAutoscaleProfile 名称
+	Profile string `json:"profile,omitempty"`
+	// Paused HPA被暂停的原因：annotation、namespace 或 global，见 PausedByAnnotation 等，未暂停时为空
+	Paused string `json:"paused,omitempty"`
+	// Schedule 生效的定时窗口名称，见 ScheduleName
+	Schedule string `json:"schedule,omitempty"`
+	// NextScheduleTime 下一次有定时窗口或日历条目开始、结束的时间，Controller 会在该时间重新协调
+	NextScheduleTime *metav1.Time `json:"nextScheduleTime,omitempty"`
+	// Calendar 引用的 ClusterAutoscaleCalendar 名称，见 CalendarName
+	Calendar string `json:"calendar,omitempty"`
+	// CalendarEntry 生效的日历条目名称，生效时优先于定时窗口
+	CalendarEntry string `json:"calendarEntry,omitempty"`
+	// ObservedAnnotationsHash 最近一次协调时自动扩缩容注解的哈希值，见 AnnotationsHash
+	ObservedAnnotationsHash string `json:"observedAnnotationsHash"`
+	// LastReconcileTime 状态最近一次发生变化时的协调时间
+	LastReconcileTime metav1.Time `json:"lastReconcileTime"`
+	// LastError 最近一次协调的错误，协调成功时为空
+	LastError string `json:"lastError,omitempty"`
+}
+
+// String 返回状态的JSON格式
+func (s *AutoscaleStatus) String() string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// ParseAutoscaleStatus 解析 status.infraflow.co/autoscale 注解的值
+func ParseAutoscaleStatus(value string) (*AutoscaleStatus, error) {
+	status := &AutoscaleStatus{}
+	if err := json.Unmarshal([]byte(value), status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// EqualAutoscaleStatus 比较两个状态是否相等，忽略 LastReconcileTime
+func EqualAutoscaleStatus(a, b *AutoscaleStatus) bool {
+	x, y := *a, *b
+	x.LastReconcileTime, y.LastReconcileTime = metav1.Time{}, metav1.Time{}
+	x.NextScheduleTime, y.NextScheduleTime = nil, nil
+	return x == y && a.NextScheduleTime.Equal(b.NextScheduleTime)
+}
+
+// AnnotationsHash 返回自动扩缩容相关注解（hpa.infraflow.co、vpa.infraflow.co 及其子域名）的哈希值，
+// 与其他注解无关，注解未变化时哈希值不变
+func AnnotationsHash(annotations map[string]string) string {
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		if consts.IsHPAAnnotation(key) || consts.IsVPAAnnotation(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, key := range keys {
+		h.Write([]byte(key))
+		h.Write([]byte{0})
+		h.Write([]byte(annotations[key]))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
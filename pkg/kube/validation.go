@@ -0,0 +1,173 @@
+package kube
+
+import (
+	stderrors "errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	autoscalev1alpha1 "github.com/infraflows/autoscale-controller/pkg/apis/autoscale/v1alpha1"
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ValidateAnnotations 校验工作负载上所有自动扩缩容相关的注解，与Controller解析注解时的规则一致，
+// 另外不允许使用未知的注解Key
+// 返回不合法的注解，以及不影响使用的警告（例如使用了已废弃的注解、工作负载类型不支持HPA）
+func ValidateAnnotations(workload client.Object, kind string) (field.ErrorList, []string) {
+	var errs field.ErrorList
+	var warnings []string
+
+	annotations := workload.GetAnnotations()
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hasHPA, hasVPA := false, false
+	unknown := map[string]bool{}
+	for _, key := range keys {
+		isHPA, isVPA := consts.IsHPAAnnotation(key), consts.IsVPAAnnotation(key)
+		if !isHPA && !isVPA {
+			continue
+		}
+		if !consts.IsKnownAnnotation(key) {
+			errs = append(errs, field.Invalid(annotationPath(key), annotations[key], "unknown annotation key"))
+			unknown[annotationPath(key).String()] = true
+			continue
+		}
+		hasHPA = hasHPA || (isHPA && !consts.IsHPAOption(key))
+		hasVPA = hasVPA || isVPA
+	}
+
+	_, deprecations := consts.NormalizeAnnotations(annotations)
+	for _, d := range deprecations {
+		warnings = append(warnings, fmt.Sprintf("annotation %s is deprecated, use %s instead", d.Key, d.Canonical))
+	}
+
+	// 未知的注解已经报告过，构建HPA/VPA时对同一注解的错误不再重复报告
+	appendErrors := func(err error) {
+		for _, e := range validationErrors(err) {
+			if !unknown[e.Field] {
+				errs = append(errs, e)
+			}
+		}
+	}
+	capabilities := CapabilitiesOf(kind)
+	if hasHPA {
+		if capabilities.HPA {
+			// 校验时无法读取引用的profile，只校验注解本身
+			var profile *autoscalev1alpha1.HPASpec
+			if ProfileName(workload) != "" {
+				profile = unknownProfile
+			}
+			_, err := HPASpecFromAnnotations(workload, profile)
+			appendErrors(err)
+		} else {
+			warnings = append(warnings, fmt.Sprintf("HPA annotations are ignored: %s cannot be scaled by a HorizontalPodAutoscaler", kind))
+		}
+	}
+	if hasVPA && capabilities.VPA {
+		_, err := VPASpecFromAnnotations(workload)
+		appendErrors(err)
+	}
+	if capabilities.HPA {
+		_, err := HPAName(workload, kind, nil)
+		appendErrors(err)
+		_, err = HPAPaused(workload)
+		appendErrors(err)
+	}
+	return errs, warnings
+}
+
+// validationErrors 返回 *ValidationError 中的错误，其他错误作为内部错误返回
+func validationErrors(err error) field.ErrorList {
+	if err == nil {
+		return nil
+	}
+	var invalid *ValidationError
+	if stderrors.As(err, &invalid) {
+		return invalid.Errors
+	}
+	return field.ErrorList{field.InternalError(field.NewPath("metadata", "annotations"), err)}
+}
+
+// ValidationError 工作负载注解的校验错误，聚合了所有不合法的注解
+// 每个错误的 Field 为 metadata.annotations[<key>]，BadValue 为注解的值
+type ValidationError struct {
+	Errors field.ErrorList
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return "invalid annotations: " + strings.Join(msgs, "; ")
+}
+
+// toError 没有校验错误时返回nil，否则返回 *ValidationError
+func toError(errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: errs}
+}
+
+// annotationPath 返回注解对应的字段路径
+func annotationPath(key string) *field.Path {
+	return field.NewPath("metadata", "annotations").Key(key)
+}
+
+// annotationParser 解析注解的值，并收集解析失败的注解
+type annotationParser struct {
+	annotations map[string]string
+	errs        field.ErrorList
+}
+
+// invalid 记录注解的值不合法
+func (p *annotationParser) invalid(key, val, reason string) {
+	p.errs = append(p.errs, field.Invalid(annotationPath(key), val, reason))
+}
+
+// required 记录缺少必需的注解
+func (p *annotationParser) required(key, reason string) {
+	p.errs = append(p.errs, field.Required(annotationPath(key), reason))
+}
+
+// int32 解析整数注解，值需要位于[min, max]区间内，注解不存在或不合法时返回nil
+func (p *annotationParser) int32(key string, min, max int32) *int32 {
+	val, ok := p.annotations[key]
+	if !ok {
+		return nil
+	}
+	v, err := strconv.ParseInt(val, 10, 32)
+	if err != nil {
+		p.invalid(key, val, "must be an integer")
+		return nil
+	}
+	if int32(v) < min || int32(v) > max {
+		p.invalid(key, val, "must be between "+strconv.Itoa(int(min))+" and "+strconv.Itoa(int(max)))
+		return nil
+	}
+	result := int32(v)
+	return &result
+}
+
+// quantity 解析资源数量注解，注解不存在或不合法时返回nil
+func (p *annotationParser) quantity(key string) *resource.Quantity {
+	val, ok := p.annotations[key]
+	if !ok {
+		return nil
+	}
+	q, err := resource.ParseQuantity(val)
+	if err != nil {
+		p.invalid(key, val, "must be a quantity, e.g. 500m or 512Mi")
+		return nil
+	}
+	return &q
+}
@@ -1,89 +1,186 @@
 package kube
 
-// import (
-// 	"encoding/json"
-// 	"fmt"
+import (
+	"encoding/json"
+	"fmt"
 
-// 	"github.com/infraflows/autoscale-controller/pkg/consts"
-// 	autoscalingv1 "k8s.io/api/autoscaling/v1"
-// 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-// 	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
-// 	"sigs.k8s.io/controller-runtime/pkg/client"
-// )
+	autoscalev1alpha1 "github.com/infraflows/autoscale-controller/pkg/apis/autoscale/v1alpha1"
+	vpav1 "github.com/infraflows/autoscale-controller/pkg/apis/vpa/v1"
+	"github.com/infraflows/autoscale-controller/pkg/consts"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
 
-// const (
-// 	// UpdateModeAuto 表示自动调整模式
-// 	UpdateModeAuto = "Auto"
-// 	// UpdateModeInitial 表示仅初始化时设置模式
-// 	UpdateModeInitial = "Initial"
-// 	// UpdateModeOff 表示禁用更新模式
-// 	UpdateModeOff = "Off"
-// )
+const (
+	// UpdateModeAuto 表示自动调整模式
+	UpdateModeAuto = "Auto"
+	// UpdateModeInitial 表示仅初始化时设置模式
+	UpdateModeInitial = "Initial"
+	// UpdateModeOff 表示禁用更新模式
+	UpdateModeOff = "Off"
+)
 
-// // ValidateUpdateMode 验证更新模式是否有效
-// func ValidateUpdateMode(mode string) error {
-// 	switch mode {
-// 	case UpdateModeAuto, UpdateModeInitial, UpdateModeOff:
-// 		return nil
-// 	default:
-// 		return fmt.Errorf("invalid update mode: %s, must be one of: %s, %s, %s",
-// 			mode, UpdateModeAuto, UpdateModeInitial, UpdateModeOff)
-// 	}
-// }
+// ValidateUpdateMode 验证更新模式是否有效
+func ValidateUpdateMode(mode string) error {
+	switch mode {
+	case UpdateModeAuto, UpdateModeInitial, UpdateModeOff:
+		return nil
+	default:
+		return fmt.Errorf("invalid update mode: %s, must be one of: %s, %s, %s",
+			mode, UpdateModeAuto, UpdateModeInitial, UpdateModeOff)
+	}
+}
 
-// // BuildDesiredVPA 根据工作负载的注解构建期望的Vertical Pod Autoscale配置
-// // 支持的注解：
-// // - vpa.infraflow.co/update-mode: 更新模式（Auto/Initial/Off）
-// // - vpa.infraflow.co/resource-policy: 资源策略（JSON格式）
-// // 如果没有指定更新模式，默认使用Auto模式
-// func BuildDesiredVPA(workload client.Object, kind string) (*v1.VerticalPodAutoscaler, error) {
-// 	annotations := workload.GetAnnotations()
-// 	vpa := &v1.VerticalPodAutoscaler{
-// 		ObjectMeta: metav1.ObjectMeta{
-// 			Name:      workload.GetName(),
-// 			Namespace: workload.GetNamespace(),
-// 		},
-// 		Spec: v1.VerticalPodAutoscalerSpec{
-// 			TargetRef: &autoscalingv1.CrossVersionObjectReference{
-// 				APIVersion: "apps/v1",
-// 				Kind:       kind,
-// 				Name:       workload.GetName(),
-// 			},
-// 		},
-// 	}
+// BuildDesiredVPA 根据VPA配置构建工作负载期望的Vertical Pod Autoscale
+// 配置来自工作负载的注解（见 VPASpecFromAnnotations）或匹配的 AutoscalePolicy，元数据见 AutoscalerObjectMeta
+// 如果没有指定更新模式，默认使用Auto模式
+// 工作负载类型不支持VPA（见 CapabilitiesOf）时返回错误
+func BuildDesiredVPA(workload client.Object, kind string, spec *autoscalev1alpha1.VPASpec) (*vpav1.VerticalPodAutoscaler, error) {
+	if !CapabilitiesOf(kind).VPA {
+		return nil, fmt.Errorf("%s does not support VerticalPodAutoscaler", kind)
+	}
+	if spec == nil {
+		return nil, fmt.Errorf("no VerticalPodAutoscaler is configured for %s %s", kind, workload.GetName())
+	}
+	spec = spec.DeepCopy()
+	mode := vpav1.UpdateMode(UpdateModeAuto)
+	if spec.UpdateMode != nil {
+		mode = *spec.UpdateMode
+	}
+	return &vpav1.VerticalPodAutoscaler{
+		ObjectMeta: AutoscalerObjectMeta(workload, kind, AutoscalerName(workload.GetName(), kind)),
+		Spec: vpav1.VerticalPodAutoscalerSpec{
+			TargetRef: &autoscalingv1.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       kind,
+				Name:       workload.GetName(),
+			},
+			UpdatePolicy: &vpav1.PodUpdatePolicy{
+				UpdateMode: &mode,
+			},
+			ResourcePolicy: spec.ResourcePolicy,
+		},
+	}, nil
+}
 
-// 	if val, ok := annotations[consts.VPAMode]; ok {
-// 		if err := ValidateUpdateMode(val); err != nil {
-// 			return nil, err
-// 		}
-// 		mode := v1.UpdateMode(val)
-// 		vpa.Spec.UpdatePolicy = &v1.PodUpdatePolicy{
-// 			UpdateMode: &mode,
-// 		}
-// 	} else {
-// 		// 如果没有指定更新模式，默认使用 Auto 模式
-// 		mode := v1.UpdateMode(UpdateModeAuto)
-// 		vpa.Spec.UpdatePolicy = &v1.PodUpdatePolicy{
-// 			UpdateMode: &mode,
-// 		}
-// 	}
+// VPASpecFromAnnotations 根据工作负载的注解构建VPA配置
+// 支持的注解：
+// - vpa.infraflow.co/updateMode: 更新模式（Auto/Initial/Off）
+// - vpa.infraflow.co/resourcePolicy: 资源策略（JSON格式）
+// - vpa.infraflow.co/containerPolicies: 容器资源策略列表（JSON格式）
+// - vpa.infraflow.co/{cpu,memory}.{minAllowed,maxAllowed}: 资源上下限简写
+// 资源策略的合并规则见 buildResourcePolicy
+// 已废弃的注解（见 consts.Aliases）会先转换为对应的新注解
+// 注解的值不合法时返回 *ValidationError，其中包含所有不合法的注解
+func VPASpecFromAnnotations(workload client.Object) (*autoscalev1alpha1.VPASpec, error) {
+	annotations, _ := consts.NormalizeAnnotations(workload.GetAnnotations())
+	spec := &autoscalev1alpha1.VPASpec{}
 
-// 	if val, ok := annotations[consts.VPAResourcePolicy]; ok {
-// 		var policy v1.PodResourcePolicy
-// 		if err := json.Unmarshal([]byte(val), &policy); err == nil {
-// 			vpa.Spec.ResourcePolicy = &policy
-// 		}
-// 	}
-// 	return vpa, nil
-// }
+	p := &annotationParser{annotations: annotations}
+	if val, ok := annotations[consts.VPAUpdateMode]; ok {
+		if err := ValidateUpdateMode(val); err != nil {
+			p.invalid(consts.VPAUpdateMode, val, "must be one of Auto, Initial, Off")
+		}
+		mode := vpav1.UpdateMode(val)
+		spec.UpdateMode = &mode
+	}
 
-// // EqualVPA 比较两个VPA配置是否相等
-// // 比较内容包括：
-// // - 目标引用名称
-// // - 更新模式
-// func EqualVPA(a, b *v1.VerticalPodAutoscaler) bool {
-// 	return a.Spec.TargetRef != nil && b.Spec.TargetRef != nil &&
-// 		a.Spec.TargetRef.Name == b.Spec.TargetRef.Name &&
-// 		a.Spec.UpdatePolicy != nil && b.Spec.UpdatePolicy != nil &&
-// 		*a.Spec.UpdatePolicy.UpdateMode == *b.Spec.UpdatePolicy.UpdateMode
-// }
+	spec.ResourcePolicy = buildResourcePolicy(p)
+	if err := toError(p.errs); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// vpaResourceBounds 资源上下限简写注解与资源名称的对应关系
+var vpaResourceBounds = []struct {
+	key      string
+	resource corev1.ResourceName
+	max      bool
+}{
+	{consts.VPACpuMinAllowed, corev1.ResourceCPU, false},
+	{consts.VPACpuMaxAllowed, corev1.ResourceCPU, true},
+	{consts.VPAMemoryMinAllowed, corev1.ResourceMemory, false},
+	{consts.VPAMemoryMaxAllowed, corev1.ResourceMemory, true},
+}
+
+// buildResourcePolicy 根据工作负载的注解构建VPA资源策略
+// 按以下顺序合并，后者优先：
+// 1. vpa.infraflow.co/resourcePolicy: 完整的PodResourcePolicy（JSON格式）
+// 2. vpa.infraflow.co/containerPolicies: ContainerResourcePolicy列表（JSON格式），
+// 按containerName替换resourcePolicy中的同名策略，其余追加
+// 3. vpa.infraflow.co/{cpu,memory}.{minAllowed,maxAllowed}: 简写注解，
+// 只覆盖通配策略（containerName为"*"）中对应资源的上下限，通配策略不存在时自动创建
+// 没有任何资源策略相关的注解时返回nil，注解不是合法的JSON/资源数量时将错误记录在p中
+func buildResourcePolicy(p *annotationParser) *vpav1.PodResourcePolicy {
+	var policy *vpav1.PodResourcePolicy
+
+	if val, ok := p.annotations[consts.VPAResourcePolicy]; ok {
+		policy = &vpav1.PodResourcePolicy{}
+		if err := json.Unmarshal([]byte(val), policy); err != nil {
+			p.invalid(consts.VPAResourcePolicy, val, "must be a JSON PodResourcePolicy: "+err.Error())
+			policy = nil
+		}
+	}
+
+	if val, ok := p.annotations[consts.VPAContainerPolicy]; ok {
+		var containerPolicies []vpav1.ContainerResourcePolicy
+		if err := json.Unmarshal([]byte(val), &containerPolicies); err != nil {
+			p.invalid(consts.VPAContainerPolicy, val, "must be a JSON list of ContainerResourcePolicy: "+err.Error())
+		} else {
+			if policy == nil {
+				policy = &vpav1.PodResourcePolicy{}
+			}
+			for _, cp := range containerPolicies {
+				*containerPolicyFor(policy, cp.ContainerName) = cp
+			}
+		}
+	}
+
+	for _, bound := range vpaResourceBounds {
+		quantity := p.quantity(bound.key)
+		if quantity == nil {
+			continue
+		}
+		if policy == nil {
+			policy = &vpav1.PodResourcePolicy{}
+		}
+		cp := containerPolicyFor(policy, vpav1.DefaultContainerResourcePolicy)
+		if bound.max {
+			if cp.MaxAllowed == nil {
+				cp.MaxAllowed = corev1.ResourceList{}
+			}
+			cp.MaxAllowed[bound.resource] = *quantity
+		} else {
+			if cp.MinAllowed == nil {
+				cp.MinAllowed = corev1.ResourceList{}
+			}
+			cp.MinAllowed[bound.resource] = *quantity
+		}
+	}
+	return policy
+}
+
+// containerPolicyFor 返回指定容器的策略，不存在时追加一条新的策略
+func containerPolicyFor(policy *vpav1.PodResourcePolicy, containerName string) *vpav1.ContainerResourcePolicy {
+	for i := range policy.ContainerPolicies {
+		if policy.ContainerPolicies[i].ContainerName == containerName {
+			return &policy.ContainerPolicies[i]
+		}
+	}
+	policy.ContainerPolicies = append(policy.ContainerPolicies, vpav1.ContainerResourcePolicy{ContainerName: containerName})
+	return &policy.ContainerPolicies[len(policy.ContainerPolicies)-1]
+}
+
+// EqualVPA 比较两个VPA配置是否相等
+// 比较内容包括：
+// - 目标引用
+// - 更新策略
+// - 资源策略
+func EqualVPA(a, b *vpav1.VerticalPodAutoscaler) bool {
+	return equality.Semantic.DeepEqual(a.Spec.TargetRef, b.Spec.TargetRef) &&
+		equality.Semantic.DeepEqual(a.Spec.UpdatePolicy, b.Spec.UpdatePolicy) &&
+		equality.Semantic.DeepEqual(a.Spec.ResourcePolicy, b.Spec.ResourcePolicy)
+}
@@ -0,0 +1,107 @@
+package kube
+
+import (
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// KindDeployment Deployment 工作负载类型
+	KindDeployment = "Deployment"
+	// KindStatefulSet StatefulSet 工作负载类型
+	KindStatefulSet = "StatefulSet"
+	// KindDaemonSet DaemonSet 工作负载类型
+	KindDaemonSet = "DaemonSet"
+)
+
+// AutoscalerName 返回为工作负载创建的 HPA / VPA 的名称
+// 同一命名空间下不同类型的工作负载可以同名，因此除 Deployment 外的类型会加上类型后缀，
+// 例如 StatefulSet api 对应 api-statefulset；Deployment 保持使用工作负载名称，与旧版本兼容
+func AutoscalerName(name, kind string) string {
+	if kind == KindDeployment {
+		return name
+	}
+	return name + "-" + strings.ToLower(kind)
+}
+
+// WorkloadKinds 支持自动扩缩容的工作负载类型
+var WorkloadKinds = []string{KindDeployment, KindStatefulSet, KindDaemonSet}
+
+// NewWorkload 返回指定类型的空工作负载对象，不支持的类型返回nil
+func NewWorkload(kind string) client.Object {
+	switch kind {
+	case KindDeployment:
+		return &appsv1.Deployment{}
+	case KindStatefulSet:
+		return &appsv1.StatefulSet{}
+	case KindDaemonSet:
+		return &appsv1.DaemonSet{}
+	}
+	return nil
+}
+
+// NewWorkloadList 返回指定类型的空工作负载列表，不支持的类型返回nil
+func NewWorkloadList(kind string) client.ObjectList {
+	switch kind {
+	case KindDeployment:
+		return &appsv1.DeploymentList{}
+	case KindStatefulSet:
+		return &appsv1.StatefulSetList{}
+	case KindDaemonSet:
+		return &appsv1.DaemonSetList{}
+	}
+	return nil
+}
+
+// PodTemplateOf 返回工作负载的Pod模板，不支持的工作负载类型返回nil
+func PodTemplateOf(workload client.Object) *corev1.PodTemplateSpec {
+	switch w := workload.(type) {
+	case *appsv1.Deployment:
+		return &w.Spec.Template
+	case *appsv1.StatefulSet:
+		return &w.Spec.Template
+	case *appsv1.DaemonSet:
+		return &w.Spec.Template
+	}
+	return nil
+}
+
+// HasContainer 检查Pod模板中是否存在指定名称的容器
+// 除普通容器外，也包括以sidecar方式运行（restartPolicy为Always）的init容器
+func HasContainer(template *corev1.PodTemplateSpec, name string) bool {
+	if template == nil {
+		return false
+	}
+	for _, c := range template.Spec.Containers {
+		if c.Name == name {
+			return true
+		}
+	}
+	for _, c := range template.Spec.InitContainers {
+		if c.Name == name && c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainerNames 返回 Pod 模板中可以被单容器资源指标引用的容器名称，
+// 包括普通容器以及以 Sidecar 方式运行（restartPolicy 为 Always）的 init 容器
+func ContainerNames(template *corev1.PodTemplateSpec) []string {
+	if template == nil {
+		return nil
+	}
+	var names []string
+	for _, c := range template.Spec.Containers {
+		names = append(names, c.Name)
+	}
+	for _, c := range template.Spec.InitContainers {
+		if c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
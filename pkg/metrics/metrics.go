@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"sync"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
@@ -13,8 +15,46 @@ var (
 		},
 		[]string{"kind"},
 	)
+
+	// CalendarEntryActive 工作负载生效的 ClusterAutoscaleCalendar 条目，生效时值为1，没有生效的条目时不存在
+	CalendarEntryActive = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "infraflow_autoscale_calendar_entry_active",
+			Help: "Calendar entry overriding the HPA replicas of the workload, 1 while active",
+		},
+		[]string{"kind", "namespace", "name", "calendar", "entry"},
+	)
+)
+
+var (
+	calendarEntriesMu sync.Mutex
+	// calendarEntries 每个工作负载最近一次记录的日历条目，key为kind、namespace、name
+	calendarEntries = map[[3]string][2]string{}
 )
 
 func Init() {
-	metrics.Registry.MustRegister(ReconcileTotal)
+	metrics.Registry.MustRegister(ReconcileTotal, CalendarEntryActive)
+}
+
+// SetCalendarEntry 记录工作负载生效的日历条目，entry为空时移除该工作负载的指标
+// 只在条目变化时删除上一次记录的序列，避免每次协调都遍历整个指标
+func SetCalendarEntry(kind, namespace, name, calendar, entry string) {
+	key := [3]string{kind, namespace, name}
+	value := [2]string{calendar, entry}
+
+	calendarEntriesMu.Lock()
+	defer calendarEntriesMu.Unlock()
+	last, ok := calendarEntries[key]
+	if ok && last == value {
+		return
+	}
+	if ok {
+		CalendarEntryActive.DeleteLabelValues(kind, namespace, name, last[0], last[1])
+	}
+	if entry == "" {
+		delete(calendarEntries, key)
+		return
+	}
+	calendarEntries[key] = value
+	CalendarEntryActive.WithLabelValues(kind, namespace, name, calendar, entry).Set(1)
 }
@@ -2,21 +2,40 @@ package metrics
 
 import (
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
 )
 
-func PrometheusExternalMetric(name string, quantity resource.Quantity) autoscalingv2.MetricSpec {
+// PrometheusExternalMetric 基于Prometheus External Metrics的HPA指标配置
+// name: 指标名称
+// selector: 指标标签选择器，可为nil
+// target: 目标值，见 AverageValueTarget 与 ValueTarget
+func PrometheusExternalMetric(name string, selector *metav1.LabelSelector, target autoscalingv2.MetricTarget) autoscalingv2.MetricSpec {
 	return autoscalingv2.MetricSpec{
 		Type: autoscalingv2.ExternalMetricSourceType,
 		External: &autoscalingv2.ExternalMetricSource{
 			Metric: autoscalingv2.MetricIdentifier{
-				Name: name,
-			},
-			Target: autoscalingv2.MetricTarget{
-				Type:         autoscalingv2.AverageValueMetricType,
-				AverageValue: &quantity,
+				Name:     name,
+				Selector: selector,
 			},
+			Target: target,
 		},
 	}
 }
+
+// AverageValueTarget 每副本平均值目标
+func AverageValueTarget(quantity resource.Quantity) autoscalingv2.MetricTarget {
+	return autoscalingv2.MetricTarget{
+		Type:         autoscalingv2.AverageValueMetricType,
+		AverageValue: &quantity,
+	}
+}
+
+// ValueTarget 指标整体值目标
+func ValueTarget(quantity resource.Quantity) autoscalingv2.MetricTarget {
+	return autoscalingv2.MetricTarget{
+		Type:  autoscalingv2.ValueMetricType,
+		Value: &quantity,
+	}
+}